@@ -0,0 +1,302 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// InfluxDB 2.x annotated csv, see https://docs.influxdata.com/influxdb/v2/reference/syntax/annotated-csv/
+const (
+	annotationDatatype = "#datatype"
+	annotationGroup    = "#group"
+	annotationDefault  = "#default"
+
+	annotatedColumnMeasurement = "_measurement"
+	annotatedColumnField       = "_field"
+	annotatedColumnValue       = "_value"
+	annotatedColumnTime        = "_time"
+	annotatedColumnResult      = "result"
+	annotatedColumnTable       = "table"
+	annotatedColumnError       = "error"
+)
+
+// annotatedCSVTable holds the annotations and header of the current table,
+// one annotated csv file can contain multiple tables separated by blank lines.
+type annotatedCSVTable struct {
+	datatypes []string
+	groups    []string
+	defaults  []string
+	columns   []string
+
+	measurementPos int
+	fieldPos       int
+	valuePos       int
+	timePos        int
+	tagsPos        []int
+	fieldsPos      []int // pivoted columns, such as the output of flux pivot()
+}
+
+func (t *annotatedCSVTable) annotation(values []string, idx int) string {
+	if idx < len(values) {
+		return values[idx]
+	}
+	return ""
+}
+
+// value returns the value of the column, the #default annotation is used for the empty value
+func (t *annotatedCSVTable) value(row []string, idx int) string {
+	if idx < 0 {
+		return ""
+	}
+	if idx < len(row) && row[idx] != "" {
+		return row[idx]
+	}
+	return t.annotation(t.defaults, idx)
+}
+
+// parseHeader parse the positions of the columns, the header is kept only if it is valid,
+// so that the rows of the invalid table are not imported
+func (t *annotatedCSVTable) parseHeader(cfg *ImportConfig, header []string) error {
+	t.measurementPos, t.fieldPos, t.valuePos, t.timePos = -1, -1, -1, -1
+	t.tagsPos, t.fieldsPos = nil, nil
+	for idx, column := range header {
+		switch column {
+		case annotatedColumnMeasurement:
+			t.measurementPos = idx
+		case annotatedColumnField:
+			t.fieldPos = idx
+		case annotatedColumnValue:
+			t.valuePos = idx
+		case annotatedColumnTime:
+			t.timePos = idx
+		case annotatedColumnError:
+			return errors.New("annotated csv contains an error table")
+		}
+	}
+	if t.timePos == -1 { // fallback to --time
+		for idx, column := range header {
+			if column == cfg.TimeField {
+				t.timePos = idx
+			}
+		}
+	}
+	if t.timePos == -1 {
+		return errors.New("time column not in annotated csv header")
+	}
+	if (t.fieldPos == -1) != (t.valuePos == -1) {
+		return errors.New("annotated csv header must contain both _field and _value columns")
+	}
+
+	for idx, column := range header {
+		if column == "" || strings.HasPrefix(column, "_") || idx == t.timePos ||
+			column == annotatedColumnResult || column == annotatedColumnTable {
+			continue
+		}
+		if _, ok := matchColumn(cfg.Drops, column); ok {
+			continue
+		}
+		if t.isTagColumn(cfg, idx, column) {
+			t.tagsPos = append(t.tagsPos, idx)
+		} else {
+			t.fieldsPos = append(t.fieldsPos, idx)
+		}
+	}
+	if t.fieldPos == -1 && len(t.fieldsPos) == 0 {
+		return errors.New("no field column in annotated csv header")
+	}
+	t.columns = header
+	return nil
+}
+
+func (t *annotatedCSVTable) isTagColumn(cfg *ImportConfig, idx int, column string) bool {
+	if _, ok := matchColumn(cfg.Tags, column); ok {
+		return true
+	}
+	if _, ok := matchColumn(cfg.Fields, column); ok {
+		return false
+	}
+	if len(t.groups) != 0 { // columns in group key are tags
+		return t.annotation(t.groups, idx) == "true"
+	}
+	return t.fieldPos != -1
+}
+
+// parseAnnotatedValue convert the string value to the type declared by #datatype
+func parseAnnotatedValue(datatype, s string) (any, error) {
+	switch {
+	case datatype == "long":
+		return strconv.ParseInt(s, 10, 64)
+	case datatype == "unsignedLong":
+		return strconv.ParseUint(s, 10, 64)
+	case datatype == "double":
+		return strconv.ParseFloat(s, 64)
+	case datatype == "boolean":
+		return strconv.ParseBool(s)
+	case strings.HasPrefix(datatype, "dateTime"):
+		return parseAnnotatedTime(datatype, s, 1)
+	case datatype == "":
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v, nil
+		}
+		return s, nil
+	default: // string, duration, tag
+		return s, nil
+	}
+}
+
+// parseAnnotatedTime parse the timestamp to nanosecond, number is multiplied by timeMultiplier
+func parseAnnotatedTime(datatype, s string, timeMultiplier int64) (int64, error) {
+	if datatype == "dateTime:number" || datatype == "long" || checkIsNumber(s) {
+		tsp, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return tsp * timeMultiplier, nil
+	}
+	tt, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return tt.UnixNano(), nil
+}
+
+func checkIsNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func (fsm *ImportFileFSM) processAnnotatedCSV(row []string) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		fsm.annotatedTable = new(annotatedCSVTable)
+		next, _ := fsm.processAnnotatedCSV(row) // never returns error in dml state
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		table := fsm.annotatedTable
+		if len(row) == 0 { // blank line, the end of the table
+			fsm.annotatedTable = new(annotatedCSVTable)
+			return FSMCallEmpty, nil
+		}
+		if strings.HasPrefix(row[0], "#") {
+			if table.columns != nil { // annotations of the next table
+				table = new(annotatedCSVTable)
+				fsm.annotatedTable = table
+			}
+			switch row[0] {
+			case annotationDatatype:
+				table.datatypes = row
+			case annotationGroup:
+				table.groups = row
+			case annotationDefault:
+				table.defaults = row
+			}
+			return FSMCallEmpty, nil
+		}
+		if table.columns == nil { // header
+			return func(ctx context.Context, command *ImportCommand) error {
+				return table.parseHeader(command.cfg, row)
+			}, nil
+		}
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" {
+				return errors.New("database is required")
+			}
+			point, err := table.point(command, row)
+			if err != nil {
+				return err
+			}
+			return command.appendPivotPoint(ctx, point)
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
+
+func (t *annotatedCSVTable) point(command *ImportCommand, row []string) (*opengemini.Point, error) {
+	if t.columns == nil {
+		return nil, errors.New("annotated csv header is required")
+	}
+	measurement := t.value(row, t.measurementPos)
+	if measurement == "" {
+		measurement = command.fsm.measurement
+	}
+	if measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+	timestamp, err := parseAnnotatedTime(t.annotation(t.datatypes, t.timePos), t.value(row, t.timePos), command.cfg.TimeMultiplier)
+	if err != nil {
+		return nil, fmt.Errorf("parse time failed: %w", err)
+	}
+
+	var point = &opengemini.Point{
+		Measurement: measurement,
+		Timestamp:   timestamp,
+		Tags:        make(map[string]string),
+		Fields:      make(map[string]interface{}),
+	}
+	for key, value := range command.fsm.staticTags {
+		point.Tags[key] = value
+	}
+	for _, idx := range t.tagsPos {
+		if value := t.value(row, idx); value != "" {
+			point.Tags[t.columns[idx]] = value
+		}
+	}
+	var addField = func(name string, idx int) error {
+		raw := t.value(row, idx)
+		if raw == "" || raw == command.cfg.NullValue {
+			return nil
+		}
+		value, err := parseAnnotatedValue(t.annotation(t.datatypes, idx), raw)
+		if err != nil {
+			return fmt.Errorf("parse field %s failed: %w", name, err)
+		}
+		point.Fields[name] = value
+		return nil
+	}
+	if t.fieldPos != -1 {
+		if err := addField(t.value(row, t.fieldPos), t.valuePos); err != nil {
+			return nil, err
+		}
+	}
+	for _, idx := range t.fieldsPos {
+		if err := addField(t.columns[idx], idx); err != nil {
+			return nil, err
+		}
+	}
+	if len(point.Fields) == 0 {
+		return nil, errors.New("all fields of the row are null")
+	}
+	return point, nil
+}
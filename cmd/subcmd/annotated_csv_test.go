@@ -0,0 +1,106 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessAnnotatedCSV(t *testing.T) {
+	content := `#group,false,false,true,true,false,false,true,true,true
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,0,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:01Z,1.5,usage_user,cpu,h1
+,,0,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:02Z,2.5,usage_user,cpu,h1
+
+#group,false,false,true,true,false,false,true,true,true
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,long,string,string,string
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,1,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:01Z,7,count,cpu,h1
+
+#group,false,false,true,false,false
+#datatype,string,long,string,dateTime:RFC3339,boolean
+#default,_result,,,,
+,result,table,_measurement,_time,online
+,,2,status,2020-01-01T00:00:01Z,true
+`
+	command, client := newMockImportCommand(t, importFormatAnnotatedCSV, content)
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+
+	var lines []string
+	for _, line := range client.writes {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	require.Equal(t, []string{
+		// the fields of same series and time are pivoted into one point
+		"cpu,host=h1 count=7i,usage_user=1.5 1577836801000000000",
+		"cpu,host=h1 usage_user=2.5 1577836802000000000",
+		"status online=true 1577836801000000000",
+	}, lines)
+}
+
+func TestProcessAnnotatedCSVInvalidHeader(t *testing.T) {
+	// the header without time column is rejected, its rows are not imported
+	content := `#datatype,string,long,double
+,result,table,usage
+,,0,1.5
+
+#datatype,string,long,string,dateTime:RFC3339,double
+,result,table,_measurement,_time,usage
+,,1,cpu,2020-01-01T00:00:01Z,2.5
+`
+	command, client := newMockImportCommand(t, importFormatAnnotatedCSV, content)
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 2, command.stats.parseErrors)
+	require.Equal(t, []string{"cpu usage=2.5 1577836801000000000"}, nonEmpty(client.writes))
+
+	var table annotatedCSVTable
+	require.Error(t, table.parseHeader(command.cfg, []string{"", "result", "table", "_time", "_field"}))
+	require.Nil(t, table.columns)
+}
+
+func TestParseAnnotatedValue(t *testing.T) {
+	for _, tcase := range []struct {
+		datatype string
+		value    string
+		expect   any
+	}{
+		{"long", "-12", int64(-12)},
+		{"unsignedLong", "12", uint64(12)},
+		{"double", "1.5", 1.5},
+		{"boolean", "false", false},
+		{"string", "12", "12"},
+		{"dateTime:RFC3339", "2020-01-01T00:00:01Z", int64(1577836801000000000)},
+		{"", "1", float64(1)},
+		{"", "abc", "abc"},
+	} {
+		t.Run(tcase.datatype, func(t *testing.T) {
+			value, err := parseAnnotatedValue(tcase.datatype, tcase.value)
+			require.NoError(t, err)
+			require.Equal(t, tcase.expect, value)
+		})
+	}
+	_, err := parseAnnotatedValue("long", "1.5")
+	require.Error(t, err)
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	adaptiveMinBatchSize = 1
+	adaptiveMaxFactor    = 16 // the max batch size is the factor times of --batch-size
+)
+
+// adaptiveTargetLatency the batch grows while the write is faster than it and shrinks while slower
+var adaptiveTargetLatency = time.Second
+
+// adaptiveBatch grows the batch size while the writes are fast and successful, and halves it on
+// slow or failed writes, so that the throughput is maximized without manual tuning.
+type adaptiveBatch struct {
+	size int
+	min  int
+	max  int
+}
+
+func newAdaptiveBatch(size int) *adaptiveBatch {
+	return &adaptiveBatch{size: size, min: adaptiveMinBatchSize, max: size * adaptiveMaxFactor}
+}
+
+// observe adjust the batch size by the result of writing n lines
+func (a *adaptiveBatch) observe(n int, latency time.Duration, err error) {
+	var size = a.size
+	switch {
+	case err != nil || latency > adaptiveTargetLatency:
+		size = max(min(a.size, n)/2, a.min)
+	case latency < adaptiveTargetLatency/2 && n >= a.size:
+		size = min(a.size+a.size/4+1, a.max)
+	}
+	if size != a.size {
+		slog.Debug("adjust batch size", "from", a.size, "to", size, "latency", latency, "error", err)
+		a.size = size
+	}
+}
+
+// batchSize returns the number of lines per batch
+func (c *ImportCommand) batchSize() int {
+	if c.adaptive != nil {
+		return c.adaptive.size
+	}
+	return c.cfg.BatchSize
+}
+
+// batchFull returns true if the buffer should be written by --batch-size, --batch-bytes or
+// --flush-interval
+func (c *ImportCommand) batchFull(lines int) bool {
+	if lines >= c.batchSize() {
+		return true
+	}
+	if c.cfg.BatchBytes > 0 && c.fsm.batchBytes >= c.cfg.BatchBytes {
+		return true
+	}
+	if c.fsm.lastFlush.IsZero() {
+		c.fsm.lastFlush = time.Now()
+	}
+	return c.cfg.FlushInterval > 0 && time.Since(c.fsm.lastFlush) >= c.cfg.FlushInterval
+}
+
+// resetBatch reset the triggers after the buffer is written
+func (c *ImportCommand) resetBatch(bytes int) {
+	c.fsm.batchBytes = bytes
+	c.fsm.lastFlush = time.Now()
+}
+
+// pointWrite returns true if the line protocol is written as points, it is parsed once when it is
+// appended
+func (c *ImportCommand) pointWrite() bool {
+	return c.cfg.ColumnWrite || c.cfg.Transport == importTransportPromRemote || c.preflight != nil || c.schema != nil
+}
+
+// appendLine append the line protocol to batch buffer, the buffer is written if it is full
+func (c *ImportCommand) appendLine(ctx context.Context, line string) error {
+	if c.pointWrite() || c.filterLine() {
+		if c.lpParser == nil {
+			c.lpParser = new(core.LineProtocolParser)
+		}
+		point, err := c.lpParser.ParseLine(line, c.cfg.TimeMultiplier)
+		if err != nil || point == nil {
+			return err
+		}
+		if c.pointWrite() {
+			return c.appendPoint(ctx, point)
+		}
+		if !c.filterTime(ctx, point.Timestamp) {
+			return nil
+		}
+	}
+	c.fsm.batchLPBuffer = append(c.fsm.batchLPBuffer, line)
+	c.fsm.batchBytes += len(line) + 1
+	if !c.batchFull(len(c.fsm.batchLPBuffer)) {
+		return nil
+	}
+	return c.excuteByLPBuffer(ctx)
+}
+
+// pointSize returns the size of the point encoded as line protocol, it is only calculated
+// when --batch-bytes is specified
+func (c *ImportCommand) pointSize(point *opengemini.Point) int {
+	if c.cfg.BatchBytes <= 0 {
+		return 0
+	}
+	line, err := core.EncodeLineProtocol([]*opengemini.Point{point})
+	if err != nil {
+		return 0
+	}
+	return len(line)
+}
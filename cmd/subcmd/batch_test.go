@@ -0,0 +1,109 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const batchContent = `# DML
+# CONTEXT-DATABASE: db0
+cpu v=1 1
+cpu v=2 2
+cpu v=3 3
+cpu v=4 4
+cpu v=5 5
+`
+
+func TestImportBatchBytes(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatLineProtocol, batchContent)
+	command.cfg.BatchBytes = 20 // two lines
+	require.NoError(t, command.process())
+	require.Equal(t, 3, client.writeCall)
+	require.Equal(t, 5, command.stats.written)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\na.c 2 1\na.d 3 1\n")
+	command.cfg.BatchBytes = 1
+	require.NoError(t, command.process())
+	require.Equal(t, 3, client.writeCall)
+}
+
+func TestImportBatchSwitchTarget(t *testing.T) {
+	content := `# DML
+# CONTEXT-DATABASE: db0
+cpu v=1 1
+# CONTEXT-MEASUREMENT: mem
+time,v
+2,1
+# DML
+# CONTEXT-DATABASE: db1
+cpu v=3 3
+# CONTEXT-RETENTION-POLICY: rp1
+cpu v=4 4
+# CONTEXT-RETENTION-POLICY: rp1
+cpu v=5 5
+`
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	require.NoError(t, command.process())
+	// the buffered lines and points are written before the database or retention policy changes
+	require.Equal(t, []string{"db0.autogen", "db0.autogen", "db1.autogen", "db1.rp1"}, client.targets)
+	require.Equal(t, []string{"cpu v=1 1", "mem v=1 2", "", "cpu v=3 3", "cpu v=4 4", "cpu v=5 5"}, client.writes)
+}
+
+func TestImportSplitTooLarge(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatLineProtocol, batchContent)
+	client.maxLines = 2
+	require.NoError(t, command.process())
+	require.Equal(t, 5, command.stats.written)
+	// 5 lines are split into 2 and 3 lines, then 3 lines are split into 1 and 2 lines
+	require.Equal(t, 5, client.writeCall)
+	require.Equal(t, []string{"cpu v=1 1", "cpu v=2 2", "cpu v=3 3", "cpu v=4 4", "cpu v=5 5"}, client.writes)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\na.c 2 1\na.d 3 1\n")
+	client.maxLines = 1
+	require.NoError(t, command.process())
+	require.Equal(t, 3, command.stats.written)
+}
+
+func TestAdaptiveBatch(t *testing.T) {
+	adaptive := newAdaptiveBatch(100)
+	adaptive.observe(100, time.Millisecond, nil)
+	require.Equal(t, 126, adaptive.size)
+	adaptive.observe(10, time.Millisecond, nil) // the partial batch does not grow the size
+	require.Equal(t, 126, adaptive.size)
+	adaptive.observe(126, 2*adaptiveTargetLatency, nil)
+	require.Equal(t, 63, adaptive.size)
+	adaptive.observe(63, time.Millisecond, errors.New("connection refused"))
+	require.Equal(t, 31, adaptive.size)
+	for i := 0; i < 100; i++ {
+		adaptive.observe(adaptive.size, time.Millisecond, nil)
+	}
+	require.Equal(t, 1600, adaptive.size)
+	for i := 0; i < 100; i++ {
+		adaptive.observe(adaptive.size, time.Millisecond, errors.New("connection refused"))
+	}
+	require.Equal(t, adaptiveMinBatchSize, adaptive.size)
+
+	command, client := newMockImportCommand(t, importFormatLineProtocol, batchContent)
+	command.cfg.BatchSize = 2
+	command.adaptive = newAdaptiveBatch(2)
+	require.NoError(t, command.process())
+	require.Equal(t, 5, command.stats.written)
+	require.Equal(t, 2, client.writeCall) // 2 lines, then the batch grows to 3 lines
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/openGemini/opengemini-client-go/proto"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+// columnEncoder encode the points to column write requests. The request builders of database and
+// the record builders of measurement are kept across batches, the buffers are reused, so that a
+// batch only allocates the records themselves. It is not thread safe.
+type columnEncoder struct {
+	username string
+	password string
+	requests map[string]opengemini.WriteRequestBuilder // by `database.rp`
+	records  map[string]opengemini.RecordBuilder       // by measurement
+	lines    []opengemini.RecordLine
+	series   map[string]int // the index of series in groups
+	groups   [][]*opengemini.Point
+}
+
+func newColumnEncoder(username, password string) *columnEncoder {
+	return &columnEncoder{
+		username: username,
+		password: password,
+		requests: make(map[string]opengemini.WriteRequestBuilder),
+		records:  make(map[string]opengemini.RecordBuilder),
+		series:   make(map[string]int),
+	}
+}
+
+// encode build the write request of the points, the points are grouped by series and each series
+// is one record. The builder sorts the rows of record by time and merges the rows of the same
+// time, so the rows of different series at the same time must not share a record.
+func (e *columnEncoder) encode(database, retentionPolicy string, points []*opengemini.Point) (*proto.WriteRequest, error) {
+	var name = database + "." + retentionPolicy
+	builder, ok := e.requests[name]
+	if !ok {
+		var err error
+		if builder, err = opengemini.NewWriteRequestBuilder(database, retentionPolicy); err != nil {
+			return nil, err
+		}
+		e.requests[name] = builder
+	}
+
+	defer e.reset()
+	for _, point := range points {
+		key := seriesName(point)
+		idx, ok := e.series[key]
+		if !ok {
+			idx = len(e.groups)
+			e.series[key] = idx
+			if idx < cap(e.groups) { // reuse the group of previous batch
+				e.groups = e.groups[:idx+1]
+			} else {
+				e.groups = append(e.groups, nil)
+			}
+		}
+		e.groups[idx] = append(e.groups[idx], point)
+	}
+
+	var request *proto.WriteRequest
+	var now = time.Now().UnixNano()
+	for _, group := range e.groups {
+		rb, err := e.recordBuilder(group[0].Measurement)
+		if err != nil {
+			return nil, err
+		}
+		e.lines = e.lines[:0]
+		for _, point := range group {
+			var timestamp = point.Timestamp
+			if timestamp == core.NoTimestamp { // the column write protocol requires the time
+				timestamp = now
+			}
+			e.lines = append(e.lines, rb.NewLine().AddTags(point.Tags).AddFields(point.Fields).Build(timestamp))
+		}
+		part, err := builder.Authenticate(e.username, e.password).AddRecord(e.lines...).Build()
+		clear(e.lines) // release the records
+		if err != nil {
+			// the error of record is kept by the builder, it is not reusable
+			delete(e.requests, name)
+			return nil, err
+		}
+		if request == nil {
+			request = part
+		} else {
+			request.Records = append(request.Records, part.Records...)
+		}
+	}
+	if request == nil {
+		return builder.Authenticate(e.username, e.password).Build()
+	}
+	return request, nil
+}
+
+func (e *columnEncoder) recordBuilder(measurement string) (opengemini.RecordBuilder, error) {
+	rb, ok := e.records[measurement]
+	if !ok {
+		var err error
+		if rb, err = opengemini.NewRecordBuilder(measurement); err != nil {
+			return nil, err
+		}
+		e.records[measurement] = rb
+	}
+	return rb, nil
+}
+
+// reset release the points of the batch, the buffers are kept
+func (e *columnEncoder) reset() {
+	clear(e.series)
+	for idx := range e.groups {
+		clear(e.groups[idx])
+		e.groups[idx] = e.groups[idx][:0]
+	}
+	e.groups = e.groups[:0]
+}
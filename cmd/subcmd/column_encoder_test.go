@@ -0,0 +1,139 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func TestColumnEncoder(t *testing.T) {
+	encoder := newColumnEncoder("user", "pass")
+	points := []*opengemini.Point{
+		{Measurement: "cpu", Tags: map[string]string{"host": "h0"}, Fields: map[string]any{"v": 1.0}, Timestamp: 3},
+		{Measurement: "mem", Fields: map[string]any{"used": int64(1)}, Timestamp: 2},
+		{Measurement: "cpu", Tags: map[string]string{"host": "h1"}, Fields: map[string]any{"v": 2.0}, Timestamp: 1},
+	}
+	for i := 0; i < 2; i++ { // the builders are reused
+		request, err := encoder.encode("db0", "rp0", points)
+		require.NoError(t, err)
+		require.Equal(t, "db0", request.Database)
+		require.Equal(t, "rp0", request.RetentionPolicy)
+		require.Equal(t, "user", request.Username)
+		// one record per series
+		var maxTimes = make(map[string][]int64)
+		for _, record := range request.Records {
+			maxTimes[record.Measurement] = append(maxTimes[record.Measurement], record.MaxTime)
+		}
+		require.Equal(t, map[string][]int64{"cpu": {3, 1}, "mem": {2}}, maxTimes)
+	}
+	require.Len(t, encoder.records, 2)
+	require.Empty(t, encoder.lines[:cap(encoder.lines)][0])
+	require.Empty(t, encoder.groups)
+
+	// the rows of different series at the same time are kept
+	request, err := encoder.encode("db0", "rp0", []*opengemini.Point{
+		{Measurement: "cpu", Tags: map[string]string{"host": "h0"}, Fields: map[string]any{"v": 1.0}, Timestamp: 1},
+		{Measurement: "cpu", Tags: map[string]string{"host": "h1"}, Fields: map[string]any{"v": 2.0}, Timestamp: 1},
+		{Measurement: "cpu", Tags: map[string]string{"host": "h0"}, Fields: map[string]any{"v": 3.0}, Timestamp: 2},
+	})
+	require.NoError(t, err)
+	require.Len(t, request.Records, 2)
+
+	// the point without timestamp is written at the current time
+	request, err = encoder.encode("db0", "rp0", []*opengemini.Point{{Measurement: "cpu", Fields: map[string]any{"v": 1.0}, Timestamp: core.NoTimestamp}})
+	require.NoError(t, err)
+	require.Greater(t, request.Records[0].MaxTime, int64(0))
+
+	// the builder with invalid record is dropped, the next batch is not affected
+	_, err = encoder.encode("db0", "rp0", []*opengemini.Point{{Measurement: "cpu", Fields: map[string]any{"time": 1.0}, Timestamp: 1}})
+	require.Error(t, err)
+	_, err = encoder.encode("db0", "rp0", points)
+	require.NoError(t, err)
+
+	_, err = encoder.encode("", "rp0", points)
+	require.Error(t, err)
+}
+
+const benchmarkLines = 20000
+
+func benchmarkImport(b *testing.B, format, content string, modify func(cfg *ImportConfig)) {
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer slog.SetDefault(logger)
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		command, _ := newMockImportCommand(b, format, content)
+		command.cfg.BatchSize = 5000
+		command.cfg.ColumnWrite = true
+		modify(command.cfg)
+		b.StartTimer()
+		require.NoError(b, command.process())
+	}
+}
+
+func BenchmarkColumnEncoder(b *testing.B) {
+	var points = make([]*opengemini.Point, 5000)
+	for i := range points {
+		points[i] = &opengemini.Point{
+			Measurement: fmt.Sprintf("m%d", i%4),
+			Tags:        map[string]string{"host": fmt.Sprintf("h%d", i%100)},
+			Fields:      map[string]any{"usage": float64(i), "idle": int64(i)},
+			Timestamp:   int64(i + 1),
+		}
+	}
+	encoder := newColumnEncoder("", "")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.encode("db0", "autogen", points); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkImportLineProtocolColumnWrite(b *testing.B) {
+	var builder strings.Builder
+	builder.WriteString("# DML\n# CONTEXT-DATABASE: db0\n")
+	for i := 0; i < benchmarkLines; i++ {
+		fmt.Fprintf(&builder, "cpu,host=h%d,region=r%d usage=%d.5,idle=%di,ok=true %d\n", i%100, i%10, i, i, i+1)
+	}
+	benchmarkImport(b, importFormatLineProtocol, builder.String(), func(cfg *ImportConfig) {})
+}
+
+func BenchmarkImportCSVColumnWrite(b *testing.B) {
+	var builder strings.Builder
+	builder.WriteString("time,host,region,usage,idle\n")
+	for i := 0; i < benchmarkLines; i++ {
+		fmt.Fprintf(&builder, "%d,h%d,r%d,%d.5,%d\n", i+1, i%100, i%10, i, i)
+	}
+	benchmarkImport(b, importFormatCSV, builder.String(), func(cfg *ImportConfig) {
+		cfg.Measurement = "cpu"
+		cfg.Tags = []string{"host", "region"}
+		cfg.Quote = `"`
+	})
+}
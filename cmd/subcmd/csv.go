@@ -0,0 +1,481 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVDialect describe the separator, quote and comment character of the csv file,
+// zero value of Quote or Comment disables it.
+type CSVDialect struct {
+	Delimiter rune
+	Quote     rune
+	Comment   rune
+}
+
+// parseDialectRune parse the flag value to a single character, support `\t` and `tab` for tab.
+func parseDialectRune(flagName, value string) (rune, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError || size != len(value) {
+		return 0, fmt.Errorf("invalid --%s value %q, must be a single character", flagName, value)
+	}
+	return r, nil
+}
+
+func newCSVDialect(cfg *ImportConfig) (CSVDialect, error) {
+	var dialect CSVDialect
+	var err error
+	if dialect.Delimiter, err = parseDialectRune("delimiter", cfg.Delimiter); err != nil {
+		return dialect, err
+	}
+	if dialect.Delimiter == 0 {
+		dialect.Delimiter = ','
+	}
+	if dialect.Quote, err = parseDialectRune("quote", cfg.Quote); err != nil {
+		return dialect, err
+	}
+	if dialect.Comment, err = parseDialectRune("comment", cfg.Comment); err != nil {
+		return dialect, err
+	}
+	if dialect.Delimiter == '\n' || dialect.Delimiter == '\r' {
+		return dialect, errors.New("invalid --delimiter, line break is not allowed")
+	}
+	if dialect.Delimiter == dialect.Quote || dialect.Delimiter == dialect.Comment ||
+		(dialect.Quote != 0 && dialect.Quote == dialect.Comment) {
+		return dialect, errors.New("--delimiter, --quote and --comment must be different characters")
+	}
+	return dialect, nil
+}
+
+// CSVReader reads records from a csv file with the configurable dialect.
+// Unlike encoding/csv, the number of fields per record is not checked,
+// and a quote character inside an unquoted field is kept as is.
+type CSVReader struct {
+	r       *bufio.Reader
+	dialect CSVDialect
+	line    int
+	// keepBlankLines returns the blank line as an empty record instead of skipping it
+	keepBlankLines bool
+}
+
+func NewCSVReader(r io.Reader, dialect CSVDialect) *CSVReader {
+	return &CSVReader{r: bufio.NewReader(r), dialect: dialect}
+}
+
+// SkipRows discard the first n lines of the file, e.g. a preamble in front of the header.
+func (r *CSVReader) SkipRows(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := r.readLine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Line returns the line number of the last read record
+func (r *CSVReader) Line() int {
+	return r.line
+}
+
+// Read reads one record, blank lines and comment lines are skipped.
+func (r *CSVReader) Read() ([]string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(line) == "" {
+			if r.keepBlankLines {
+				return []string{}, nil
+			}
+			continue
+		}
+		if r.dialect.Comment != 0 && strings.HasPrefix(line, string(r.dialect.Comment)) {
+			continue
+		}
+		return r.parseRecord(line)
+	}
+}
+
+func (r *CSVReader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	r.line++
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, nil
+}
+
+func (r *CSVReader) parseRecord(line string) ([]string, error) {
+	var record []string
+	var field strings.Builder
+	var quoted bool  // current field starts with quote
+	var inQuote bool // inside the quoted section
+	var startLine = r.line
+	for {
+		for i := 0; i < len(line); {
+			ch, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			switch {
+			case inQuote && ch == r.dialect.Quote:
+				next, nextSize := utf8.DecodeRuneInString(line[i:])
+				if i < len(line) && next == r.dialect.Quote { // escaped quote
+					field.WriteRune(ch)
+					i += nextSize
+					continue
+				}
+				inQuote = false
+			case inQuote:
+				field.WriteRune(ch)
+			case ch == r.dialect.Delimiter:
+				record = append(record, field.String())
+				field.Reset()
+				quoted = false
+			case ch == r.dialect.Quote && r.dialect.Quote != 0 && field.Len() == 0 && !quoted:
+				quoted = true
+				inQuote = true
+			default:
+				field.WriteRune(ch)
+			}
+		}
+		if !inQuote {
+			break
+		}
+		// the quoted field contains line break, continue with the next line
+		next, err := r.readLine()
+		if err != nil {
+			if err == io.EOF {
+				return nil, &csvQuoteError{line: startLine, quote: r.dialect.Quote}
+			}
+			return nil, err
+		}
+		field.WriteByte('\n')
+		line = next
+	}
+	record = append(record, field.String())
+	return record, nil
+}
+
+// csvQuoteError the quoted field is not closed before the end of input
+type csvQuoteError struct {
+	line  int
+	quote rune
+}
+
+func (e *csvQuoteError) Error() string {
+	return fmt.Sprintf("line %d: extraneous or missing %q in quoted-field", e.line, e.quote)
+}
+
+// parseKeyValues parse flag values like `key=value` into map
+func parseKeyValues(flagName string, kvs []string) (map[string]string, error) {
+	var result = make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		key, value, ok := strings.Cut(kv, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s value %q, must be key=value", flagName, kv)
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// isColumnPattern check the column selector is a glob pattern, such as `temp_*`
+func isColumnPattern(selector string) bool {
+	return strings.ContainsAny(selector, "*?[")
+}
+
+// matchColumn check the column name match one of selectors, selector support glob pattern
+func matchColumn(selectors []string, column string) (string, bool) {
+	for _, selector := range selectors {
+		if selector == column {
+			return selector, true
+		}
+		if !isColumnPattern(selector) {
+			continue
+		}
+		if matched, _ := path.Match(selector, column); matched {
+			return selector, true
+		}
+	}
+	return "", false
+}
+
+// parseCSVHeader build the column mapping of the csv file by header and import config.
+// The --rename is applied to the header before column selection, so --tags, --fields,
+// --time, --measurement-column and --drop refer to the renamed column names.
+func (fsm *ImportFileFSM) parseCSVHeader(cfg *ImportConfig, header []string) error {
+	renames, err := parseKeyValues("rename", cfg.Renames)
+	if err != nil {
+		return err
+	}
+	fsm.tagMap = make(map[string]FieldPos)
+	fsm.fieldMap = make(map[string]FieldPos)
+	fsm.timeField = FieldPos{}
+	fsm.measurementField = FieldPos{Pos: -1}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // jump BOM
+	}
+
+	var matchedTags = make(map[string]bool)
+	var matchedFields = make(map[string]bool)
+	for idx, column := range header { // column name
+		if newName, ok := renames[column]; ok {
+			column = newName
+		}
+		if _, ok := matchColumn(cfg.Drops, column); ok {
+			slog.Info("drop column name", "column", column)
+			continue
+		}
+		if cfg.MeasurementColumn != "" && cfg.MeasurementColumn == column {
+			fsm.measurementField = FieldPos{column, idx}
+			continue
+		}
+		if cfg.TimeField == column {
+			fsm.timeField = FieldPos{column, idx}
+			continue
+		}
+		tagSelector, isTag := matchColumn(cfg.Tags, column)
+		fieldSelector, isField := matchColumn(cfg.Fields, column)
+		if isTag && isField && !isColumnPattern(tagSelector) && !isColumnPattern(fieldSelector) {
+			return errors.New(column + " is in both tags and fields")
+		}
+		if isTag { // tags take precedence over fields when selected by pattern
+			fsm.tagMap[column] = FieldPos{column, idx}
+			matchedTags[tagSelector] = true
+			continue
+		}
+		if isField {
+			fsm.fieldMap[column] = FieldPos{column, idx}
+			matchedFields[fieldSelector] = true
+			continue
+		}
+
+		if len(cfg.Fields) == 0 { // If --field is not specified, the remaining column are fields.
+			fsm.fieldMap[column] = FieldPos{column, idx}
+		} else {
+			slog.Info("ignore column name", "column", column)
+		}
+	}
+
+	for _, field := range cfg.Fields {
+		if !matchedFields[field] && !isColumnPattern(field) {
+			return fmt.Errorf("field name (%s) not in csv header", field)
+		}
+	}
+	for _, tag := range cfg.Tags {
+		if !matchedTags[tag] && !isColumnPattern(tag) {
+			return fmt.Errorf("tag name (%s) not in csv header", tag)
+		}
+	}
+	for tag := range fsm.staticTags {
+		if _, exist := fsm.fieldMap[tag]; exist {
+			return errors.New(tag + " is in both tags and fields")
+		}
+	}
+
+	if cfg.MeasurementColumn != "" && fsm.measurementField.Name == "" {
+		return errors.New("measurement column not in csv header " + cfg.MeasurementColumn)
+	}
+	if fsm.timeField.Name == "" {
+		return errors.New("time name not in csv header " + cfg.TimeField)
+	}
+	return nil
+}
+
+// csvRowMeasurement returns the measurement name of the csv row, the value of --measurement-column
+// takes precedence over --measurement.
+func (fsm *ImportFileFSM) csvRowMeasurement(row []string) string {
+	if fsm.measurementField.Name != "" && fsm.measurementField.Pos < len(row) {
+		if measurement := strings.TrimSpace(row[fsm.measurementField.Pos]); measurement != "" {
+			return measurement
+		}
+	}
+	return fsm.measurement
+}
+
+// csvCell returns the value of the column, false if the row is too short or the value is null
+func csvCell(row []string, pos int, nullValue string) (string, bool) {
+	if pos < 0 || pos >= len(row) {
+		return "", false
+	}
+	value := row[pos]
+	if value == "" || value == nullValue {
+		return "", false
+	}
+	return value, true
+}
+
+// csvSection is the embedded csv rows of line protocol file, declared by the CONTEXT-MEASUREMENT,
+// CONTEXT-TAGS, CONTEXT-FIELDS and CONTEXT-TIME directives which override the --measurement,
+// --tags, --fields and --time flags. The first line after the directives is the csv header,
+// the section ends at the next `# DML` or directive. The quoted cell may contain line breaks.
+//
+//	# CONTEXT-MEASUREMENT: cpu
+//	# CONTEXT-TAGS: host
+//	# CONTEXT-TIME: ts
+//	ts,host,usage
+//	1700000000000000000,h1,0.5
+type csvSection struct {
+	measurement *string
+	tags        []string
+	fields      []string
+	timeField   *string
+	header      bool
+	pending     string // the lines of the record whose quoted cell is not closed
+}
+
+// parseContextDirective returns true if the line is a csv section directive
+func (fsm *ImportFileFSM) parseContextDirective(data string) bool {
+	var value string
+	var token string
+	for _, token = range []string{importTokenMeasurement, importTokenTags, importTokenFields, importTokenTimeField} {
+		if v, ok := strings.CutPrefix(data, token); ok {
+			value = strings.TrimSpace(v)
+			break
+		}
+		token = ""
+	}
+	if token == "" {
+		return false
+	}
+	if fsm.csvSection == nil || fsm.csvSection.header { // directives of the next section
+		fsm.csvSection = new(csvSection)
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	switch token {
+	case importTokenMeasurement:
+		fsm.csvSection.measurement = &value
+	case importTokenTags:
+		fsm.csvSection.tags = list
+	case importTokenFields:
+		fsm.csvSection.fields = list
+	case importTokenTimeField:
+		fsm.csvSection.timeField = &value
+	}
+	return true
+}
+
+// config returns the import config overridden by the directives
+func (s *csvSection) config(cfg *ImportConfig) *ImportConfig {
+	var sectionCfg = *cfg
+	if s.measurement != nil {
+		sectionCfg.Measurement = *s.measurement
+		sectionCfg.MeasurementColumn = ""
+	}
+	if s.tags != nil {
+		sectionCfg.Tags = s.tags
+	}
+	if s.fields != nil {
+		sectionCfg.Fields = s.fields
+	}
+	if s.timeField != nil {
+		sectionCfg.TimeField = *s.timeField
+	}
+	return &sectionCfg
+}
+
+func (fsm *ImportFileFSM) processCSVSection(data string) FSMCall {
+	section := fsm.csvSection
+	return func(ctx context.Context, command *ImportCommand) error {
+		if fsm.database == "" {
+			return errors.New("database is required, make sure `# CONTEXT-DATABASE:` token is exist")
+		}
+		cfg := section.config(command.cfg)
+		dialect, err := newCSVDialect(cfg)
+		if err != nil {
+			return err
+		}
+		dialect.Comment = 0
+		section.pending += data
+		row, err := NewCSVReader(strings.NewReader(section.pending), dialect).Read()
+		var quoteErr *csvQuoteError
+		if errors.As(err, &quoteErr) { // the quoted cell continues in the next line
+			return nil
+		}
+		section.pending = ""
+		if err != nil {
+			return err
+		}
+		isHeader := !section.header
+		section.header = true
+		if isHeader {
+			fsm.measurement = cfg.Measurement
+			if err = fsm.parseCSVHeader(cfg, row); err != nil {
+				fsm.fieldMap = nil
+				return err
+			}
+			return nil
+		}
+		if fsm.fieldMap == nil {
+			return errors.New("csv header of the section is invalid")
+		}
+		point, err := fsm.csvPoint(command, row)
+		if err != nil {
+			return err
+		}
+		// the section is mixed with line protocol, its fields are typed as line protocol does
+		for name, value := range point.Fields {
+			point.Fields[name] = csvFieldValue(value.(string))
+		}
+		return command.appendPoint(ctx, point)
+	}
+}
+
+// csvSectionError returns the error if the file ends inside a quoted cell of the csv section
+func (fsm *ImportFileFSM) csvSectionError() error {
+	if fsm.csvSection == nil || fsm.csvSection.pending == "" {
+		return nil
+	}
+	return fmt.Errorf("quoted cell of csv section is not closed: %q", strings.TrimSpace(fsm.csvSection.pending))
+}
+
+// csvFieldValue returns the boolean or float of the csv cell, the other cell is string
+func csvFieldValue(cell string) any {
+	switch cell {
+	case "t", "T", "true", "True", "TRUE":
+		return true
+	case "f", "F", "false", "False", "FALSE":
+		return false
+	}
+	if v, err := strconv.ParseFloat(cell, 64); err == nil {
+		return v
+	}
+	return cell
+}
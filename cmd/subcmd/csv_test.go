@@ -0,0 +1,208 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func TestParseCSVHeader(t *testing.T) {
+	cfg := &ImportConfig{CommandLineConfig: new(core.CommandLineConfig)}
+	cfg.Measurement = "plc"
+	cfg.MeasurementColumn = "kind"
+	cfg.TimeField = "ts"
+	cfg.Tags = []string{"line", "station_*"}
+	cfg.Fields = []string{"temp_*", "pressure"}
+	cfg.Renames = []string{"time=ts", "press=pressure"}
+	cfg.Drops = []string{"temp_raw"}
+
+	fsm := &ImportFileFSM{staticTags: map[string]string{"site": "berlin"}}
+	header := []string{"\ufefftime", "kind", "line", "station_a", "temp_1", "temp_raw", "press", "comment"}
+	require.NoError(t, fsm.parseCSVHeader(cfg, header))
+
+	require.Equal(t, FieldPos{"ts", 0}, fsm.timeField)
+	require.Equal(t, FieldPos{"kind", 1}, fsm.measurementField)
+	require.Equal(t, map[string]FieldPos{"line": {"line", 2}, "station_a": {"station_a", 3}}, fsm.tagMap)
+	require.Equal(t, map[string]FieldPos{"temp_1": {"temp_1", 4}, "pressure": {"pressure", 6}}, fsm.fieldMap)
+
+	fsm.measurement = cfg.Measurement
+	require.Equal(t, "boiler", fsm.csvRowMeasurement([]string{"1", "boiler"}))
+	require.Equal(t, "plc", fsm.csvRowMeasurement([]string{"1", ""}))
+}
+
+func TestParseCSVHeaderError(t *testing.T) {
+	for _, tcase := range []struct {
+		name   string
+		modify func(cfg *ImportConfig)
+	}{
+		{"field not in header", func(cfg *ImportConfig) { cfg.Fields = []string{"missing"} }},
+		{"tag not in header", func(cfg *ImportConfig) { cfg.Tags = []string{"missing"} }},
+		{"tag and field", func(cfg *ImportConfig) { cfg.Tags = []string{"v1"}; cfg.Fields = []string{"v1"} }},
+		{"measurement column", func(cfg *ImportConfig) { cfg.MeasurementColumn = "missing" }},
+		{"invalid rename", func(cfg *ImportConfig) { cfg.Renames = []string{"=v2"} }},
+		{"time not in header", func(cfg *ImportConfig) { cfg.TimeField = "ts" }},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			cfg := &ImportConfig{CommandLineConfig: new(core.CommandLineConfig), TimeField: "time"}
+			tcase.modify(cfg)
+			fsm := new(ImportFileFSM)
+			require.Error(t, fsm.parseCSVHeader(cfg, []string{"time", "v1", "v2"}))
+		})
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	type testCase struct {
+		name     string
+		dialect  CSVDialect
+		skipRows int
+		input    string
+		expect   [][]string
+	}
+	testCases := []testCase{
+		{
+			name:    "default",
+			dialect: CSVDialect{Delimiter: ',', Quote: '"', Comment: '#'},
+			input:   "# comment\ntime,v1\r\n1,\"a,\"\"b\"\"\"\n\n2,\"multi\nline\"\n3",
+			expect:  [][]string{{"time", "v1"}, {"1", "a,\"b\""}, {"2", "multi\nline"}, {"3"}},
+		},
+		{
+			name:     "semicolon with preamble",
+			dialect:  CSVDialect{Delimiter: ';', Quote: '\''},
+			skipRows: 2,
+			input:    "exported by plc\nversion 2\ntime;v1;v2\n1;'x;y';\n#2;3;4",
+			expect:   [][]string{{"time", "v1", "v2"}, {"1", "x;y", ""}, {"#2", "3", "4"}},
+		},
+		{
+			name:    "tab without quote",
+			dialect: CSVDialect{Delimiter: '\t'},
+			input:   "1\t\"a\"\tb\n",
+			expect:  [][]string{{"1", "\"a\"", "b"}},
+		},
+	}
+	for _, tcase := range testCases {
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewCSVReader(strings.NewReader(tcase.input), tcase.dialect)
+			require.NoError(t, reader.SkipRows(tcase.skipRows))
+			var records [][]string
+			for {
+				record, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				records = append(records, record)
+			}
+			require.Equal(t, tcase.expect, records)
+		})
+	}
+
+	reader := NewCSVReader(strings.NewReader("1,\"open"), CSVDialect{Delimiter: ',', Quote: '"'})
+	_, err := reader.Read()
+	require.Error(t, err)
+}
+
+func TestNewCSVDialect(t *testing.T) {
+	cfg := &ImportConfig{CommandLineConfig: new(core.CommandLineConfig), Delimiter: `\t`, Quote: "\"", Comment: ""}
+	dialect, err := newCSVDialect(cfg)
+	require.NoError(t, err)
+	require.Equal(t, CSVDialect{Delimiter: '\t', Quote: '"'}, dialect)
+
+	cfg.Delimiter = ";;"
+	_, err = newCSVDialect(cfg)
+	require.Error(t, err)
+
+	cfg.Delimiter = "\""
+	_, err = newCSVDialect(cfg)
+	require.Error(t, err)
+}
+
+func TestCSVCell(t *testing.T) {
+	row := []string{"1", "", "NULL", "v"}
+	for _, tcase := range []struct {
+		pos    int
+		value  string
+		exists bool
+	}{
+		{0, "1", true},
+		{1, "", false},
+		{2, "", false},
+		{3, "v", true},
+		{4, "", false},
+	} {
+		value, ok := csvCell(row, tcase.pos, "NULL")
+		require.Equal(t, tcase.value, value)
+		require.Equal(t, tcase.exists, ok)
+	}
+}
+
+func TestProcessLineProtocolCSVSectionQuotedLineBreak(t *testing.T) {
+	content := `# DML
+# CONTEXT-DATABASE: db0
+# CONTEXT-MEASUREMENT: event
+# CONTEXT-TIME: ts
+ts,host,note
+1,"h1","first
+
+# not a directive"
+2,h2,"unclosed
+`
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Quote = `"`
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 1, command.stats.parseErrors)
+	require.Equal(t, "event host=\"h1\",note=\"first\n\n# not a directive\" 1\n", strings.Join(client.writes, "\n"))
+}
+
+func TestProcessLineProtocolCSVSection(t *testing.T) {
+	content := `# DDL
+CREATE DATABASE db0
+# DML
+# CONTEXT-DATABASE: db0
+cpu,host=h0 usage=0.1 1
+# CONTEXT-MEASUREMENT: cpu
+# CONTEXT-TAGS: host
+# CONTEXT-TIME: ts
+ts,host,usage
+2,h1,0.5
+3,"h,2",
+
+# CONTEXT-MEASUREMENT: mem
+# CONTEXT-FIELDS: used
+time,region,used
+4,eu,10
+# DML
+# CONTEXT-DATABASE: db0
+mem,region=us used=1 5
+`
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Measurement = "ignored"
+	command.cfg.Quote = `"`
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, []string{"CREATE DATABASE db0"}, client.queries)
+	require.Equal(t, []string{
+		"cpu,host=h0 usage=0.1 1",
+		"mem,region=us used=1 5",
+		"cpu,host=h1 usage=0.5 2",
+		"mem used=10 4",
+		"",
+	}, client.writes)
+}
@@ -0,0 +1,348 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/openGemini/openGemini-cli/common"
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	exportFormatParquet = "parquet"
+	exportTimeColumn    = "time"
+)
+
+type ExportConfig struct {
+	*core.CommandLineConfig
+	Path        string
+	Format      string
+	Measurement string
+	Start       string
+	End         string
+	BatchSize   int
+}
+
+type ExportCommand struct {
+	cfg        *ExportConfig
+	httpClient core.HttpClient
+}
+
+func (c *ExportCommand) Run(config *ExportConfig) error {
+	if config.Format == "" {
+		config.Format = exportFormatParquet
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = common.DefaultBatchSize
+	}
+	if config.RetentionPolicy == "" {
+		config.RetentionPolicy = common.DefaultRetentionPolicy
+	}
+	httpClient, err := core.NewHttpClient(config.CommandLineConfig)
+	if err != nil {
+		slog.Error("create http client failed", "reason", err)
+		return err
+	}
+	c.httpClient = httpClient
+	c.cfg = config
+	return c.process()
+}
+
+func (c *ExportCommand) process() error {
+	if c.cfg.Format != exportFormatParquet {
+		return fmt.Errorf("unknown --format %s, only support parquet", c.cfg.Format)
+	}
+	if c.cfg.Database == "" || c.cfg.Measurement == "" {
+		return errors.New("database and measurement are required")
+	}
+	var ctx = context.Background()
+	schema, err := c.parquetSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(c.cfg.Path)
+	if err != nil {
+		slog.Error("create file failed", "file", c.cfg.Path, "reason", err)
+		return err
+	}
+	defer file.Close()
+	rows, err := c.writeParquet(ctx, file, schema)
+	if err != nil {
+		return err
+	}
+	slog.Info("export finished", "path", c.cfg.Path, "rows", rows)
+	return nil
+}
+
+// query execute the command and returns the first series, nil if the result is empty
+func (c *ExportCommand) query(ctx context.Context, command string) (*opengemini.Series, error) {
+	result, err := c.httpClient.Query(ctx, &opengemini.Query{
+		Database:        c.cfg.Database,
+		RetentionPolicy: c.cfg.RetentionPolicy,
+		Command:         command,
+		Precision:       opengemini.PrecisionRFC3339,
+	})
+	if err != nil {
+		slog.Error("execute query failed", "reason", err, "command", command)
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	for _, res := range result.Results {
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		if len(res.Series) != 0 {
+			return res.Series[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// parquetSchema build the schema by tag keys and field keys of the measurement, the tag is
+// optional string column and the field is optional column of its type
+func (c *ExportCommand) parquetSchema(ctx context.Context) (*parquet.Schema, error) {
+	var measurement = quoteIdentifier(c.cfg.Measurement)
+	var group = parquet.Group{exportTimeColumn: parquet.Timestamp(parquet.Nanosecond)}
+	tags, err := c.query(ctx, "SHOW TAG KEYS FROM "+measurement)
+	if err != nil {
+		return nil, err
+	}
+	if tags != nil {
+		for _, value := range tags.Values {
+			if name, ok := value[0].(string); ok {
+				group[name] = parquet.Optional(parquet.String())
+			}
+		}
+	}
+	fields, err := c.query(ctx, "SHOW FIELD KEYS FROM "+measurement)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("measurement %s not found", c.cfg.Measurement)
+	}
+	for _, value := range fields.Values {
+		name, _ := value[0].(string)
+		typ, _ := value[1].(string)
+		var node parquet.Node
+		switch typ {
+		case "float":
+			node = parquet.Leaf(parquet.DoubleType)
+		case "integer":
+			node = parquet.Int(64)
+		case "unsigned":
+			node = parquet.Uint(64)
+		case "boolean":
+			node = parquet.Leaf(parquet.BooleanType)
+		default:
+			node = parquet.String()
+		}
+		group[name] = parquet.Optional(node)
+	}
+	return parquet.NewSchema(c.cfg.Measurement, group), nil
+}
+
+// timeConditions returns the conditions of --start and --end
+func (c *ExportCommand) timeConditions() ([]string, error) {
+	var conditions []string
+	for _, bound := range []struct {
+		value string
+		op    string
+	}{{c.cfg.Start, ">="}, {c.cfg.End, "<"}} {
+		if bound.value == "" {
+			continue
+		}
+		tt, err := time.Parse(time.RFC3339Nano, bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %s: %w", bound.value, err)
+		}
+		conditions = append(conditions, fmt.Sprintf("time %s %d", bound.op, tt.UnixNano()))
+	}
+	return conditions, nil
+}
+
+// selectRows returns the statement selecting the measurement by the conditions
+func (c *ExportCommand) selectRows(conditions []string, suffix string) string {
+	var where string
+	if len(conditions) != 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return fmt.Sprintf("SELECT * FROM %s.%s%s%s", quoteIdentifier(c.cfg.RetentionPolicy), quoteIdentifier(c.cfg.Measurement), where, suffix)
+}
+
+// writeParquet query the measurement page by page and write the rows to parquet file. The next page
+// starts after the time of the last row, the rows of series sharing the last time are queried at
+// once, so that the page boundary does not split them.
+func (c *ExportCommand) writeParquet(ctx context.Context, w io.Writer, schema *parquet.Schema) (int64, error) {
+	conditions, err := c.timeConditions()
+	if err != nil {
+		return 0, err
+	}
+	var leaves = make(map[string]parquet.LeafColumn)
+	for _, path := range schema.Columns() {
+		leaves[path[0]], _ = schema.Lookup(path...)
+	}
+	writer := parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy))
+	var total int64
+	var write = func(series *opengemini.Series, values []opengemini.SeriesValue) error {
+		var rows = make([]parquet.Row, 0, len(values))
+		for _, value := range values {
+			row, err := parquetRow(leaves, series.Columns, value)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		if _, err := writer.WriteRows(rows); err != nil {
+			return err
+		}
+		total += int64(len(rows))
+		return nil
+	}
+	var page = conditions
+	for {
+		series, err := c.query(ctx, c.selectRows(page, fmt.Sprintf(" ORDER BY time LIMIT %d", c.cfg.BatchSize)))
+		if err != nil {
+			return total, err
+		}
+		if series == nil || len(series.Values) == 0 {
+			break
+		}
+		if len(series.Values) < c.cfg.BatchSize {
+			if err = write(series, series.Values); err != nil {
+				return total, err
+			}
+			break
+		}
+		last, err := rowTime(series, len(series.Values)-1)
+		if err != nil {
+			return total, err
+		}
+		var end = len(series.Values)
+		for end > 0 {
+			if tt, err := rowTime(series, end-1); err != nil || tt != last {
+				break
+			}
+			end--
+		}
+		if err = write(series, series.Values[:end]); err != nil {
+			return total, err
+		}
+		lastRows, err := c.query(ctx, c.selectRows(append(conditions[:len(conditions):len(conditions)], fmt.Sprintf("time = %d", last)), ""))
+		if err != nil {
+			return total, err
+		}
+		if lastRows != nil {
+			if err = write(lastRows, lastRows.Values); err != nil {
+				return total, err
+			}
+		}
+		page = append(conditions[:len(conditions):len(conditions)], fmt.Sprintf("time > %d", last))
+	}
+	return total, writer.Close()
+}
+
+// rowTime returns the unix nano time of the row of query result
+func rowTime(series *opengemini.Series, row int) (int64, error) {
+	for idx, column := range series.Columns {
+		if column != exportTimeColumn || idx >= len(series.Values[row]) {
+			continue
+		}
+		s, _ := series.Values[row][idx].(string)
+		tt, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %v", series.Values[row][idx])
+		}
+		return tt.UnixNano(), nil
+	}
+	return 0, errors.New("time column is missing from query result")
+}
+
+// parquetRow convert the query result row to parquet row, the column missing from the schema is ignored
+func parquetRow(leaves map[string]parquet.LeafColumn, columns []string, values []any) (parquet.Row, error) {
+	var row = make(parquet.Row, len(leaves))
+	for _, leaf := range leaves {
+		row[leaf.ColumnIndex] = parquet.NullValue().Level(0, 0, leaf.ColumnIndex)
+	}
+	for idx, column := range columns {
+		leaf, ok := leaves[column]
+		if !ok || idx >= len(values) || values[idx] == nil {
+			continue
+		}
+		value, err := parquetValueOf(leaf.Node.Type(), column, values[idx])
+		if err != nil {
+			return nil, err
+		}
+		row[leaf.ColumnIndex] = value.Level(0, leaf.MaxDefinitionLevel, leaf.ColumnIndex)
+	}
+	return row, nil
+}
+
+// parquetValueOf convert the json value of query result to the type of parquet column
+func parquetValueOf(typ parquet.Type, column string, v any) (parquet.Value, error) {
+	if column == exportTimeColumn {
+		s, _ := v.(string)
+		tt, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return parquet.Value{}, fmt.Errorf("invalid time %v", v)
+		}
+		return parquet.Int64Value(tt.UnixNano()), nil
+	}
+	switch typ.Kind() {
+	case parquet.Double:
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return parquet.DoubleValue(f), nil
+			}
+		}
+	case parquet.Int64:
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return parquet.Int64Value(i), nil
+			}
+			// the unsigned greater than math.MaxInt64 keeps its bits
+			if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+				return parquet.Int64Value(int64(u)), nil
+			}
+		}
+	case parquet.Boolean:
+		if b, ok := v.(bool); ok {
+			return parquet.BooleanValue(b), nil
+		}
+	case parquet.ByteArray:
+		return parquet.ByteArrayValue([]byte(fmt.Sprint(v))), nil
+	}
+	return parquet.Value{}, fmt.Errorf("invalid value %v of column %s", v, column)
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
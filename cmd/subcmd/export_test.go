@@ -0,0 +1,99 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func TestExportParquet(t *testing.T) {
+	client := new(mockHttpClient)
+	client.responder = func(command string) *opengemini.QueryResult {
+		var series *opengemini.Series
+		switch {
+		case strings.HasPrefix(command, "SHOW TAG KEYS"):
+			series = &opengemini.Series{Columns: []string{"tagKey"}, Values: opengemini.SeriesValues{{"host"}}}
+		case strings.HasPrefix(command, "SHOW FIELD KEYS"):
+			series = &opengemini.Series{Columns: []string{"fieldKey", "fieldType"},
+				Values: opengemini.SeriesValues{{"usage", "float"}, {"count", "integer"}, {"ok", "boolean"}, {"note", "string"}}}
+		case strings.HasSuffix(command, "1577836800000000000 ORDER BY time LIMIT 2"):
+			// the page ends in the middle of the rows at 2020-01-01T00:00:02Z
+			series = &opengemini.Series{Columns: []string{"time", "count", "host", "note", "ok", "usage"},
+				Values: opengemini.SeriesValues{
+					{"2020-01-01T00:00:01.000000001Z", json.Number("3"), "h1", "x", true, json.Number("1.5")},
+					{"2020-01-01T00:00:02Z", json.Number("4"), nil, nil, false, nil},
+				}}
+		case strings.HasSuffix(command, "time = 1577836802000000000"):
+			series = &opengemini.Series{Columns: []string{"time", "count", "host", "note", "ok", "usage"},
+				Values: opengemini.SeriesValues{
+					{"2020-01-01T00:00:02Z", json.Number("4"), nil, nil, false, nil},
+					{"2020-01-01T00:00:02Z", json.Number("9007199254740993"), "h3", nil, nil, nil},
+				}}
+		case strings.HasSuffix(command, "time > 1577836802000000000 ORDER BY time LIMIT 2"):
+			series = &opengemini.Series{Columns: []string{"time", "count", "host", "note", "ok", "usage"},
+				Values: opengemini.SeriesValues{{"2020-01-01T00:00:03Z", json.Number("5"), "h2", nil, nil, json.Number("2.5")}}}
+		default:
+			return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{}}}
+		}
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Series: []*opengemini.Series{series}}}}
+	}
+	path := filepath.Join(t.TempDir(), "cpu.parquet")
+	cfg := &ExportConfig{CommandLineConfig: new(core.CommandLineConfig), Path: path, Format: exportFormatParquet,
+		Measurement: "cpu", BatchSize: 2, Start: "2020-01-01T00:00:00Z"}
+	cfg.Database = "db0"
+	cfg.RetentionPolicy = "autogen"
+	command := &ExportCommand{cfg: cfg, httpClient: client}
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		`SHOW TAG KEYS FROM "cpu"`,
+		`SHOW FIELD KEYS FROM "cpu"`,
+		`SELECT * FROM "autogen"."cpu" WHERE time >= 1577836800000000000 ORDER BY time LIMIT 2`,
+		`SELECT * FROM "autogen"."cpu" WHERE time >= 1577836800000000000 AND time = 1577836802000000000`,
+		`SELECT * FROM "autogen"."cpu" WHERE time >= 1577836800000000000 AND time > 1577836802000000000 ORDER BY time LIMIT 2`,
+	}, client.queries)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	stat, err := file.Stat()
+	require.NoError(t, err)
+	reader, err := NewParquetReader(file, stat.Size())
+	require.NoError(t, err)
+	require.Equal(t, []string{"count", "host", "note", "ok", "time", "usage"}, reader.Columns())
+	var rows [][]any
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	require.Equal(t, [][]any{
+		{int64(3), "h1", "x", true, time.Unix(1577836801, 1).UTC(), 1.5},
+		{int64(4), nil, nil, false, time.Unix(1577836802, 0).UTC(), nil},
+		{int64(9007199254740993), "h3", nil, nil, time.Unix(1577836802, 0).UTC(), nil},
+		{int64(5), "h2", nil, nil, time.Unix(1577836803, 0).UTC(), 2.5},
+	}, rows)
+}
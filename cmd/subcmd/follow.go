@@ -0,0 +1,436 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFlushInterval = time.Second
+	followOffsetSuffix   = ".offset"
+)
+
+// followPollInterval the interval of checking new data after EOF
+var followPollInterval = 250 * time.Millisecond
+
+var errFollowNoData = errors.New("no complete line available")
+
+// FollowReader reads the complete lines of a growing file like `tail -F`, the truncated file is
+// read from the beginning, and the rotated file is drained before the new file is opened.
+type FollowReader struct {
+	path     string
+	file     *os.File
+	reader   *bufio.Reader
+	partial  []byte // the incomplete last line
+	offset   int64  // the offset behind the last complete line
+	reopened bool
+}
+
+// NewFollowReader open the file and seek to offset, the file is read from the beginning if it is
+// shorter than offset.
+func NewFollowReader(path string, offset int64) (*FollowReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if offset > stat.Size() {
+		slog.Warn("file is shorter than the saved offset, read from the beginning", "path", path, "offset", offset)
+		offset = 0
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &FollowReader{path: path, file: file, reader: bufio.NewReader(file), offset: offset}, nil
+}
+
+// ReadLine returns the next complete line, errFollowNoData if there is no complete line now
+func (r *FollowReader) ReadLine() (string, error) {
+	line, err := r.reader.ReadBytes('\n')
+	r.partial = append(r.partial, line...)
+	if err == nil {
+		line := string(r.partial)
+		r.offset += int64(len(r.partial))
+		r.partial = r.partial[:0]
+		return line, nil
+	}
+	if err != io.EOF {
+		return "", err
+	}
+	return r.checkFile()
+}
+
+// checkFile detect truncation and rotation at EOF
+func (r *FollowReader) checkFile() (string, error) {
+	current, err := r.file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if current.Size() < r.offset+int64(len(r.partial)) {
+		slog.Info("file truncated, read from the beginning", "path", r.path)
+		if _, err = r.file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		r.reset(r.file)
+		return "", errFollowNoData
+	}
+	latest, err := os.Stat(r.path)
+	if err != nil || os.SameFile(current, latest) { // the rotated file may not be created yet
+		return "", errFollowNoData
+	}
+	file, err := os.Open(r.path)
+	if err != nil {
+		return "", errFollowNoData
+	}
+	slog.Info("file rotated, read the new file", "path", r.path)
+	_ = r.file.Close()
+	var last = string(r.partial) // the old file ends without line break
+	r.reset(file)
+	if last != "" {
+		return last, nil
+	}
+	return "", errFollowNoData
+}
+
+func (r *FollowReader) reset(file *os.File) {
+	r.file = file
+	r.reader.Reset(file)
+	r.partial = r.partial[:0]
+	r.offset = 0
+	r.reopened = true
+}
+
+// Reopened returns true once after the file is truncated or rotated
+func (r *FollowReader) Reopened() bool {
+	reopened := r.reopened
+	r.reopened = false
+	return reopened
+}
+
+// Offset returns the offset behind the last complete line
+func (r *FollowReader) Offset() int64 {
+	return r.offset
+}
+
+// Rewind seek back to offset of the current file, the lines behind it are read again
+func (r *FollowReader) Rewind(offset int64) error {
+	if _, err := r.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.reader.Reset(r.file)
+	r.partial = r.partial[:0]
+	r.offset = offset
+	return nil
+}
+
+func (r *FollowReader) Close() error {
+	return r.file.Close()
+}
+
+// followOffset is the content of the offset file, the offset is only valid for the same file
+type followOffset struct {
+	Path    string    `json:"path"`
+	Offset  int64     `json:"offset"`
+	Device  uint64    `json:"device,omitempty"`
+	Inode   uint64    `json:"inode,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// sameFile compare the device and inode, or the size and modification time if they are not
+// available, the appended file is never smaller or older.
+func (o *followOffset) sameFile(info os.FileInfo) bool {
+	if device, inode, ok := fileIdentity(info); ok && o.Inode != 0 {
+		return device == o.Device && inode == o.Inode
+	}
+	return info.Size() >= o.Size && !info.ModTime().Before(o.ModTime)
+}
+
+func (c *ImportCommand) offsetFile() string {
+	if c.cfg.OffsetFile != "" {
+		return c.cfg.OffsetFile
+	}
+	return c.cfg.Path + followOffsetSuffix
+}
+
+func (c *ImportCommand) loadOffset() (int64, error) {
+	content, err := os.ReadFile(c.offsetFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var saved followOffset
+	if err = json.Unmarshal(content, &saved); err != nil {
+		return 0, fmt.Errorf("invalid offset file %s: %w", c.offsetFile(), err)
+	}
+	if info, err := os.Stat(c.cfg.Path); err == nil && !saved.sameFile(info) {
+		slog.Warn("file is replaced after the offset is saved, read from the beginning", "path", c.cfg.Path, "offset", saved.Offset)
+		return 0, nil
+	}
+	return saved.Offset, nil
+}
+
+// saveOffset write the offset of the reader to a temporary file and rename it, so that the offset
+// file is never partially written
+func (c *ImportCommand) saveOffset(reader *FollowReader) error {
+	info, err := reader.file.Stat()
+	if err != nil {
+		return err
+	}
+	var saved = followOffset{Path: c.cfg.Path, Offset: reader.Offset(), Size: info.Size(), ModTime: info.ModTime()}
+	saved.Device, saved.Inode, _ = fileIdentity(info)
+	content, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	tmp := c.offsetFile() + ".tmp"
+	if err = os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.offsetFile())
+}
+
+// followState holds the per-file state of follow mode
+type followState struct {
+	skipRows int
+}
+
+// startFollowFile prepare the fsm for the beginning of a file, the csv header is parsed again
+func (c *ImportCommand) startFollowFile(ctx context.Context, state *followState) error {
+	state.skipRows = c.cfg.SkipRows
+	if c.cfg.Format != importFormatCSV {
+		return nil
+	}
+	c.fsm.state = importStateDDL
+	if c.cfg.NoHeader {
+		if len(c.cfg.Columns) == 0 {
+			return errors.New("--columns is required when --no-header is specified")
+		}
+		fsmCall, _ := c.fsm.processCSV(c.cfg.Columns)
+		return fsmCall(ctx, c)
+	}
+	return nil
+}
+
+// followLine process one line by the fsm of the format, replay only restores the fsm state by
+// the directives and header in front of the saved offset without writing data.
+func (c *ImportCommand) followLine(ctx context.Context, state *followState, line string, replay bool) error {
+	if state.skipRows > 0 {
+		state.skipRows--
+		return nil
+	}
+	switch c.cfg.Format {
+	case importFormatLineProtocol:
+		isHeader := c.fsm.csvSection != nil && !c.fsm.csvSection.header
+		if replay && !strings.HasPrefix(line, "#") && !isHeader {
+			return nil
+		}
+		if replay && c.fsm.state == importStateDDL && !strings.HasPrefix(line, "#") {
+			return nil // the ddl was executed before
+		}
+		fsmCall, err := c.fsm.processLineProtocol(line)
+		if err != nil {
+			return err
+		}
+		return fsmCall(ctx, c)
+	case importFormatCSV:
+		if replay && c.fsm.state != importStateDDL {
+			return nil
+		}
+		dialect, err := newCSVDialect(c.cfg)
+		if err != nil {
+			return err
+		}
+		row, err := NewCSVReader(strings.NewReader(line), dialect).Read()
+		if err == io.EOF { // blank or comment line
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fsmCall, err := c.fsm.processCSV(row)
+		if err != nil {
+			return err
+		}
+		return fsmCall(ctx, c)
+	}
+	return fmt.Errorf("--follow only support line_protocol and csv, got %s", c.cfg.Format)
+}
+
+// replay restores the fsm state by the lines of file in front of offset
+func (c *ImportCommand) replay(ctx context.Context, state *followState, file io.ReaderAt, offset int64) error {
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, offset))
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err = c.followLine(ctx, state, line, true); err != nil {
+			slog.Error("replay line failed", "reason", err, "line", strings.TrimSpace(line))
+		}
+	}
+}
+
+// follow keeps reading the file after EOF until it is interrupted, the partial batch is flushed
+// every --flush-interval and the offset is saved after every flush.
+func (c *ImportCommand) follow(ctx context.Context) error {
+	switch c.cfg.Format {
+	case importFormatLineProtocol, importFormatCSV:
+	default:
+		return fmt.Errorf("--follow only support line_protocol and csv, got %s", c.cfg.Format)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if c.cfg.FlushInterval <= 0 {
+		c.cfg.FlushInterval = defaultFlushInterval
+	}
+
+	offset, err := c.loadOffset()
+	if err != nil {
+		return err
+	}
+	reader, err := NewFollowReader(c.cfg.Path, offset)
+	if err != nil {
+		slog.Error("open file failed", "file", c.cfg.Path, "reason", err)
+		return err
+	}
+	defer reader.Close()
+
+	var state = new(followState)
+	if err = c.startFollowFile(ctx, state); err != nil {
+		return err
+	}
+	if reader.Offset() > 0 {
+		if err = c.replay(ctx, state, reader.file, reader.Offset()); err != nil {
+			return err
+		}
+		slog.Info("resume from saved offset", "path", c.cfg.Path, "offset", reader.Offset())
+	}
+
+	// the offset stops advancing once a batch is not written, the lines behind the saved offset
+	// are read and written again at the next flush. The rejected lines are never written, they
+	// don't stop the offset.
+	var saved = reader.Offset()
+	var stalled bool
+	var stall = func(err error) {
+		var writeErr *importWriteError
+		if !errors.As(err, &writeErr) || writeErr.rejected() {
+			return
+		}
+		if !stalled {
+			slog.Warn("the lines are not written, the saved offset stops advancing", "offset", saved)
+		}
+		stalled = true
+	}
+	var rewind = func() error {
+		slog.Info("write the lines behind the saved offset again", "path", c.cfg.Path, "offset", saved)
+		if err := reader.Rewind(saved); err != nil {
+			return err
+		}
+		stalled = false
+		c.fsm = &ImportFileFSM{staticTags: c.fsm.staticTags}
+		if err := c.startFollowFile(ctx, state); err != nil {
+			return err
+		}
+		return c.replay(ctx, state, reader.file, saved)
+	}
+	var flush = func() error {
+		if err := c.fsm.clearBuffer()(ctx, c); err != nil {
+			stall(err)
+			if err := c.onError(err, "clear buffer failed"); err != nil {
+				return err
+			}
+		}
+		if stalled {
+			return rewind()
+		}
+		if err := c.saveOffset(reader); err != nil {
+			slog.Error("save offset failed", "file", c.offsetFile(), "reason", err)
+		}
+		saved = reader.Offset()
+		return nil
+	}
+	var lastFlush = time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := flush(); err != nil {
+				return err
+			}
+			slog.Info("follow stopped", "path", c.cfg.Path, "offset", reader.Offset())
+			return nil
+		default:
+		}
+		line, err := reader.ReadLine()
+		if reader.Reopened() {
+			if stalled { // the failed lines of the old file cannot be read again
+				slog.Warn("file is reopened, the lines behind the saved offset are not written", "path", c.cfg.Path, "offset", saved)
+				stalled = false
+			}
+			saved = 0
+			if err := c.startFollowFile(ctx, state); err != nil {
+				slog.Error("prepare new file failed", "reason", err)
+			}
+		}
+		switch {
+		case err == nil:
+			if err = c.followLine(ctx, state, line, false); err != nil {
+				stall(err)
+				if err := c.onError(err, "process line failed", "line", strings.TrimSpace(line)); err != nil {
+					return err
+				}
+			}
+		case errors.Is(err, errFollowNoData):
+			select {
+			case <-ctx.Done():
+			case <-time.After(followPollInterval):
+			}
+		default:
+			slog.Error("read line failed", "reason", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(followPollInterval):
+			}
+		}
+		if time.Since(lastFlush) >= c.cfg.FlushInterval {
+			if err := flush(); err != nil {
+				return err
+			}
+			lastFlush = time.Now()
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openGemini/openGemini-cli/core"
+	"github.com/stretchr/testify/require"
+)
+
+func appendFile(t *testing.T, path, content string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestFollowReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	appendFile(t, path, "a\nb")
+	reader, err := NewFollowReader(path, 0)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "a\n", line)
+	_, err = reader.ReadLine()
+	require.ErrorIs(t, err, errFollowNoData)
+	require.Equal(t, int64(2), reader.Offset())
+
+	appendFile(t, path, "c\n")
+	line, err = reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "bc\n", line)
+	require.Equal(t, int64(5), reader.Offset())
+
+	// truncate
+	require.NoError(t, os.WriteFile(path, []byte("d\n"), 0600))
+	_, err = reader.ReadLine()
+	require.ErrorIs(t, err, errFollowNoData)
+	require.True(t, reader.Reopened())
+	line, err = reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "d\n", line)
+
+	// rotate, the last line of old file without line break is returned
+	appendFile(t, path, "e")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "f\n")
+	line, err = reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "e", line)
+	require.True(t, reader.Reopened())
+	line, err = reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "f\n", line)
+	require.Equal(t, int64(2), reader.Offset())
+}
+
+func runFollow(t *testing.T, command *ImportCommand, during func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- command.follow(ctx) }()
+	during()
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestImportFollow(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	content := "# DDL\nCREATE DATABASE db0\n# DML\n# CONTEXT-DATABASE: db0\ncpu v=1 1\n"
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Follow = true
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	runFollow(t, command, func() {
+		time.Sleep(50 * time.Millisecond)
+		appendFile(t, command.cfg.Path, "cpu v=2 2\ncpu v=")
+		time.Sleep(50 * time.Millisecond)
+	})
+	require.Equal(t, []string{"CREATE DATABASE db0"}, client.queries)
+	require.Equal(t, []string{"cpu v=1 1", "cpu v=2 2"}, nonEmpty(client.writes))
+	offset, err := command.loadOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)+len("cpu v=2 2\n")), offset)
+
+	// restart from the saved offset, the directives are replayed without executing ddl
+	appendFile(t, command.cfg.Path, "3 3\n")
+	restarted, client := newMockImportCommand(t, importFormatLineProtocol, "")
+	restarted.cfg = command.cfg
+	runFollow(t, restarted, func() { time.Sleep(50 * time.Millisecond) })
+	require.Empty(t, client.queries)
+	require.Equal(t, []string{"cpu v=3 3"}, nonEmpty(client.writes))
+}
+
+func TestImportFollowWriteFailed(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	content := "# DML\n# CONTEXT-DATABASE: db0\ncpu v=1 1\n"
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Follow = true
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	client.writeErr = errors.New("connection refused")
+	runFollow(t, command, func() { time.Sleep(50 * time.Millisecond) })
+	offset, err := command.loadOffset()
+	require.NoError(t, err)
+	require.Zero(t, offset)
+
+	// the unwritten lines are read again after the restart
+	restarted, client := newMockImportCommand(t, importFormatLineProtocol, "")
+	restarted.cfg = command.cfg
+	runFollow(t, restarted, func() { time.Sleep(50 * time.Millisecond) })
+	require.Equal(t, []string{"cpu v=1 1"}, nonEmpty(client.writes))
+	offset, err = restarted.loadOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), offset)
+}
+
+func TestImportFollowWriteRecovered(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	content := "# DML\n# CONTEXT-DATABASE: db0\ncpu v=1 1\n"
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Follow = true
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	client.writeErr = errors.New("connection refused")
+	client.failWrites = 2
+	runFollow(t, command, func() { time.Sleep(100 * time.Millisecond) })
+	require.Equal(t, []string{"cpu v=1 1"}, nonEmpty(client.writes))
+	offset, err := command.loadOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), offset)
+}
+
+func TestImportFollowWriteRejected(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	content := "# DML\n# CONTEXT-DATABASE: db0\ncpu v=1 1\n"
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Follow = true
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	client.writeErr = &core.WriteError{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}
+	runFollow(t, command, func() { time.Sleep(50 * time.Millisecond) })
+	require.Equal(t, 1, client.writeCall) // the rejected lines are not written again
+	offset, err := command.loadOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), offset)
+}
+
+func TestImportFollowFileReplaced(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	content := "# DML\n# CONTEXT-DATABASE: db0\ncpu v=1 1\n"
+	command, _ := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Follow = true
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	runFollow(t, command, func() { time.Sleep(50 * time.Millisecond) })
+	offset, err := command.loadOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), offset)
+
+	// the new file is not smaller than the offset, but it is read from the beginning
+	replaced := "# DML\n# CONTEXT-DATABASE: db0\ncpu v=2 2\ncpu v=3 3\n"
+	require.NoError(t, os.WriteFile(command.cfg.Path+".new", []byte(replaced), 0600))
+	require.NoError(t, os.Rename(command.cfg.Path+".new", command.cfg.Path))
+	offset, err = command.loadOffset()
+	require.NoError(t, err)
+	require.Zero(t, offset)
+
+	restarted, client := newMockImportCommand(t, importFormatLineProtocol, "")
+	restarted.cfg = command.cfg
+	runFollow(t, restarted, func() { time.Sleep(50 * time.Millisecond) })
+	require.Equal(t, []string{"cpu v=2 2", "cpu v=3 3"}, nonEmpty(client.writes))
+}
+
+func TestImportFollowCSV(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	command, client := newMockImportCommand(t, importFormatCSV, "time,v\n1,a\n")
+	command.cfg.Follow = true
+	command.cfg.Measurement = "m"
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	command.cfg.Quote = `"`
+	runFollow(t, command, func() {
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, os.Rename(command.cfg.Path, command.cfg.Path+".1"))
+		appendFile(t, command.cfg.Path, "time,w\n2,b\n")
+		time.Sleep(50 * time.Millisecond)
+	})
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries) // the database is checked once
+	writeClient := command.writeClient.(*mockWriteClient)
+	var records int
+	for _, request := range writeClient.requests {
+		records += len(request.Records)
+	}
+	require.Equal(t, 2, records)
+}
+
+func nonEmpty(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
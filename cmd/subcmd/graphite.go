@@ -0,0 +1,266 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/openGemini/openGemini-cli/core"
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// Graphite plaintext protocol, see https://graphite.readthedocs.io/en/latest/feeding-carbon.html
+const (
+	graphiteSeparator         = "."
+	graphiteMeasurement       = "measurement"
+	graphiteMeasurementGreedy = "measurement*"
+	graphiteField             = "field"
+	graphiteFieldGreedy       = "field*"
+)
+
+// GraphiteTemplate maps the dotted path segments to measurement, tags and field, the syntax is
+// `[filter] template [default tags]`, such as `servers.* .host.measurement.field region=us`.
+// The template part is `measurement`, `field`, the greedy `measurement*` and `field*`, empty to
+// skip the segment, or a tag name. The segments of the same part are joined by dot.
+type GraphiteTemplate struct {
+	filter []string
+	parts  []string
+	tags   map[string]string
+}
+
+func NewGraphiteTemplate(s string) (*GraphiteTemplate, error) {
+	var t = &GraphiteTemplate{tags: make(map[string]string)}
+	var fields = strings.Fields(s)
+	if len(fields) != 0 && strings.Contains(fields[len(fields)-1], "=") {
+		for _, tag := range strings.Split(fields[len(fields)-1], ",") {
+			key, value, ok := strings.Cut(tag, "=")
+			if !ok || key == "" || value == "" {
+				return nil, fmt.Errorf("invalid graphite template %q, invalid tag %q", s, tag)
+			}
+			t.tags[key] = value
+		}
+		fields = fields[:len(fields)-1]
+	}
+	var template string
+	switch len(fields) {
+	case 1:
+		template = fields[0]
+	case 2:
+		t.filter = strings.Split(fields[0], graphiteSeparator)
+		for _, segment := range t.filter {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid graphite template %q, invalid filter: %w", s, err)
+			}
+		}
+		template = fields[1]
+	default:
+		return nil, fmt.Errorf("invalid graphite template %q", s)
+	}
+	t.parts = strings.Split(template, graphiteSeparator)
+	var hasMeasurement bool
+	for idx, part := range t.parts {
+		switch part {
+		case graphiteMeasurement, graphiteMeasurementGreedy:
+			hasMeasurement = true
+		}
+		if (part == graphiteMeasurementGreedy || part == graphiteFieldGreedy) && idx != len(t.parts)-1 {
+			return nil, fmt.Errorf("invalid graphite template %q, %s must be the last part", s, part)
+		}
+	}
+	if !hasMeasurement {
+		return nil, fmt.Errorf("invalid graphite template %q, measurement is required", s)
+	}
+	return t, nil
+}
+
+// match returns the count of filter segments if the path matches the filter, -1 if not match
+func (t *GraphiteTemplate) match(segments []string) int {
+	if len(t.filter) > len(segments) {
+		return -1
+	}
+	for idx, pattern := range t.filter {
+		if ok, _ := path.Match(pattern, segments[idx]); !ok {
+			return -1
+		}
+	}
+	return len(t.filter)
+}
+
+// Apply returns the measurement, tags and field of the path, field is empty if not in template
+func (t *GraphiteTemplate) Apply(segments []string) (string, map[string]string, string) {
+	var measurement, field []string
+	var tags = make(map[string]string)
+	var seen = make(map[string]bool)
+	for key, value := range t.tags {
+		tags[key] = value
+	}
+	for idx, part := range t.parts {
+		if idx >= len(segments) {
+			break
+		}
+		switch part {
+		case "":
+		case graphiteMeasurement:
+			measurement = append(measurement, segments[idx])
+		case graphiteMeasurementGreedy:
+			measurement = append(measurement, segments[idx:]...)
+		case graphiteField:
+			field = append(field, segments[idx])
+		case graphiteFieldGreedy:
+			field = append(field, segments[idx:]...)
+		default:
+			if seen[part] {
+				tags[part] += graphiteSeparator + segments[idx]
+			} else {
+				tags[part] = segments[idx]
+				seen[part] = true
+			}
+		}
+	}
+	return strings.Join(measurement, graphiteSeparator), tags, strings.Join(field, graphiteSeparator)
+}
+
+// GraphiteParser choose the most specific template of the path, the template without filter is
+// the default one, the whole path is the measurement if no template matches.
+type GraphiteParser struct {
+	templates []*GraphiteTemplate
+}
+
+func NewGraphiteParser(templates []string) (*GraphiteParser, error) {
+	var parser = new(GraphiteParser)
+	for _, s := range templates {
+		t, err := NewGraphiteTemplate(s)
+		if err != nil {
+			return nil, err
+		}
+		parser.templates = append(parser.templates, t)
+	}
+	defaultTemplate, _ := NewGraphiteTemplate(graphiteMeasurementGreedy)
+	parser.templates = append(parser.templates, defaultTemplate)
+	return parser, nil
+}
+
+// Parse parse line like `servers.host01.cpu.user 42.5 1356998400`, the tags of graphite 1.1 such
+// as `cpu.user;host=host01` is supported. The timestamp is core.NoTimestamp if absent.
+func (p *GraphiteParser) Parse(line string, fieldName string) (*opengemini.Point, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid graphite line %q, expect '<path> <value> [timestamp]'", line)
+	}
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", parts[1])
+	}
+	var timestamp = core.NoTimestamp
+	if len(parts) == 3 && parts[2] != "-1" { // -1 means now in carbon
+		if timestamp, err = parsePromTimestamp(parts[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	metricPath, pathTags, _ := strings.Cut(parts[0], ";")
+	segments := strings.Split(metricPath, graphiteSeparator)
+	var template *GraphiteTemplate
+	var specific = -1
+	for _, t := range p.templates {
+		if n := t.match(segments); n > specific {
+			template, specific = t, n
+		}
+	}
+	measurement, tags, field := template.Apply(segments)
+	if measurement == "" {
+		return nil, fmt.Errorf("no measurement in path %q", metricPath)
+	}
+	if field == "" {
+		field = fieldName
+	}
+	if pathTags != "" {
+		for _, tag := range strings.Split(pathTags, ";") {
+			key, value, ok := strings.Cut(tag, "=")
+			if !ok || key == "" || value == "" {
+				return nil, fmt.Errorf("invalid tag %q", tag)
+			}
+			tags[key] = value
+		}
+	}
+	return &opengemini.Point{
+		Measurement: measurement,
+		Timestamp:   timestamp,
+		Tags:        tags,
+		Fields:      map[string]interface{}{field: value},
+	}, nil
+}
+
+func (fsm *ImportFileFSM) processGraphite(line string) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		next, nextErr := fsm.processGraphite(line)
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			var err error
+			if fsm.defaultTime, err = command.parseDefaultTime(); err != nil {
+				return err
+			}
+			if fsm.graphiteParser, err = NewGraphiteParser(command.cfg.Templates); err != nil {
+				return err
+			}
+			if nextErr != nil {
+				return nextErr
+			}
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return FSMCallEmpty, nil
+		}
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" || fsm.graphiteParser == nil {
+				return errors.New("database is required")
+			}
+			var fieldName = promFieldValue
+			if len(command.cfg.Fields) != 0 {
+				fieldName = command.cfg.Fields[0]
+			}
+			point, err := fsm.graphiteParser.Parse(line, fieldName)
+			if err != nil {
+				return err
+			}
+			for _, value := range point.Fields {
+				if command.skipValue(value.(float64), "line", line) {
+					return nil
+				}
+			}
+			if point.Timestamp == core.NoTimestamp {
+				point.Timestamp = fsm.defaultTime
+			}
+			for key, value := range fsm.staticTags {
+				point.Tags[key] = value
+			}
+			return command.appendPivotPoint(ctx, point)
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
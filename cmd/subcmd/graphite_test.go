@@ -0,0 +1,95 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"testing"
+
+	"github.com/openGemini/openGemini-cli/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphiteParser(t *testing.T) {
+	parser, err := NewGraphiteParser([]string{
+		"servers.* .host.measurement.field region=us",
+		"servers.*.disk .host.measurement.device.device.field",
+		"app.* measurement.measurement*",
+	})
+	require.NoError(t, err)
+
+	type testCase struct {
+		line        string
+		measurement string
+		tags        map[string]string
+		field       string
+		timestamp   int64
+	}
+	for _, tcase := range []testCase{
+		{"servers.h1.cpu.user 42.5 1356998400", "cpu", map[string]string{"host": "h1", "region": "us"}, "user", 1356998400000000000},
+		{"servers.h1.disk.sda.1.free 10 1356998400", "disk", map[string]string{"host": "h1", "device": "sda.1"}, "free", 1356998400000000000},
+		{"app.web.req.count 3", "app.web.req.count", map[string]string{}, "value", core.NoTimestamp},
+		{"load.avg;host=h2;dc=eu 0.5 -1", "load.avg", map[string]string{"host": "h2", "dc": "eu"}, "value", core.NoTimestamp},
+		{"load.avg 0.5 0", "load.avg", map[string]string{}, "value", 0},
+	} {
+		point, err := parser.Parse(tcase.line, "value")
+		require.NoError(t, err, tcase.line)
+		require.Equal(t, tcase.measurement, point.Measurement, tcase.line)
+		require.Equal(t, tcase.tags, point.Tags, tcase.line)
+		require.Contains(t, point.Fields, tcase.field, tcase.line)
+		require.Equal(t, tcase.timestamp, point.Timestamp, tcase.line)
+	}
+
+	for _, line := range []string{"servers.h1.cpu", "servers.h1.cpu x 1", "a.b 1 2 3"} {
+		_, err = parser.Parse(line, "value")
+		require.Error(t, err, line)
+	}
+}
+
+func TestProcessGraphiteInvalidTemplate(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatGraphite, "servers.h1.cpu.user 1 1356998400\n")
+	command.cfg.Templates = []string{"host.field"}
+	requireExitCode(t, ExitCodeFailure, command.process())
+	require.Empty(t, client.queries)
+}
+
+func TestNewGraphiteTemplateError(t *testing.T) {
+	for _, template := range []string{"host.field", "measurement*.host", "a b c", "measurement host=", "[ measurement"} {
+		_, err := NewGraphiteTemplate(template)
+		require.Error(t, err, template)
+	}
+}
+
+func TestProcessGraphite(t *testing.T) {
+	content := `servers.h1.cpu.user 1 1356998400
+servers.h1.cpu.system 2 1356998400
+servers.h1.cpu.idle x 1356998400
+servers.h1.cpu.iowait NaN 1356998400
+servers.h1.cpu.steal +Inf 1356998400
+servers.h2.cpu.user 3
+servers.h3.cpu.user 4 0
+`
+	command, client := newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.Templates = []string{"servers.* .host.measurement.field"}
+	command.cfg.DefaultTime = "2013-01-01T00:00:01Z"
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 2, command.stats.skipped)
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{
+		"cpu,host=h1 system=2,user=1 1356998400000000000",
+		"cpu,host=h2 user=3 1356998401000000000",
+		"cpu,host=h3 user=4 0",
+		"",
+	}, client.writes)
+}
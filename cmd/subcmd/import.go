@@ -17,12 +17,11 @@ package subcmd
 import (
 	"bufio"
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -36,6 +35,9 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	protobuf "google.golang.org/protobuf/proto"
 
 	"github.com/openGemini/openGemini-cli/common"
 	"github.com/openGemini/openGemini-cli/core"
@@ -46,6 +48,12 @@ const (
 	importFormatCSV          = "csv"
 	importFormatJSONInflux   = "jsoni"
 	importFormatJSONProm     = "jsonp"
+	importFormatAnnotatedCSV = "annotated_csv"
+	importFormatPromText     = "prom_text"
+	importFormatNDJSON       = "ndjson"
+	importFormatOpenTSDB     = "opentsdb"
+	importFormatGraphite     = "graphite"
+	importFormatParquet      = "parquet"
 
 	importTokenDDL             = "# DDL"
 	importTokenDML             = "# DML"
@@ -57,10 +65,6 @@ const (
 	importTokenTimeField       = "# CONTEXT-TIME:"
 )
 
-var (
-	builderEntities = make(map[string]opengemini.WriteRequestBuilder)
-)
-
 func NewColumnWriterClient(cfg *ImportConfig) (proto.WriteServiceClient, error) {
 	var dialOptions = []grpc.DialOption{
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
@@ -93,7 +97,32 @@ func NewColumnWriterClient(cfg *ImportConfig) (proto.WriteServiceClient, error)
 	} else {
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
-	conn, err := grpc.NewClient(cfg.Host+":"+strconv.Itoa(cfg.ColumnWritePort), dialOptions...)
+	token, err := core.NewTokenSource(cfg.CommandLineConfig)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(core.TokenCredentials{Source: token}))
+	}
+	// the port of --host is the http port, the column write service listens on --column-write-port of each host
+	endpoints, err := core.ParseEndpoints(cfg.Host, cfg.ColumnWritePort)
+	if err != nil {
+		return nil, err
+	}
+	var addresses = make([]resolver.Address, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		host, _, _ := net.SplitHostPort(endpoint)
+		addresses = append(addresses, resolver.Address{Addr: net.JoinHostPort(host, strconv.Itoa(cfg.ColumnWritePort))})
+	}
+	var target = addresses[0].Addr
+	if len(addresses) > 1 {
+		builder := manual.NewBuilderWithScheme("opengemini")
+		builder.InitialState(resolver.State{Addresses: addresses})
+		dialOptions = append(dialOptions, grpc.WithResolvers(builder),
+			grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`))
+		target = builder.Scheme() + ":///column-write"
+	}
+	conn, err := grpc.NewClient(target, dialOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -102,21 +131,70 @@ func NewColumnWriterClient(cfg *ImportConfig) (proto.WriteServiceClient, error)
 
 type ImportConfig struct {
 	*core.CommandLineConfig
-	Path            string
-	Format          string
-	ColumnWrite     bool
-	ColumnWritePort int
-	BatchSize       int
-	Tags            []string
-	Fields          []string
-	TimeField       string
+	Path              string
+	Format            string
+	ColumnWrite       bool
+	ColumnWritePort   int
+	Transport         string
+	BatchSize         int
+	Tags              []string
+	Fields            []string
+	TimeField         string
+	TimeFieldOptional bool // --time is not passed, the ndjson document without the time key has no timestamp
+	MeasurementColumn string
+	AddTags           []string
+	Renames           []string
+	Drops             []string
+	Delimiter         string
+	Quote             string
+	Comment           string
+	SkipRows          int
+	NoHeader          bool
+	Columns           []string
+	NullValue         string
+	Mapping           string
+	Templates         []string
+	Follow            bool
+	FlushInterval     time.Duration
+	OffsetFile        string
+	OnError           string
+	MaxErrors         int
+	BatchBytes        int
+	AdaptiveBatch     bool
+	ControlFile       string
+	Preflight         bool
+	Coerce            bool
+	Start             string
+	End               string
+	RetentionCheck    string
+	DefaultTime       string
+	RejectFile        string
+	CreateRP          string
+	RPDuration        string
+	RPReplication     int
+	RPShardDuration   string
+	RPDefault         bool
+	RPAlter           bool
+	RateLimit
 }
 
 type ImportCommand struct {
 	cfg         *ImportConfig
 	httpClient  core.HttpClient
 	writeClient proto.WriteServiceClient
+	encoder     *columnEncoder
+	lpParser    *core.LineProtocolParser
 	fsm         *ImportFileFSM
+	stats       importStats
+	adaptive    *adaptiveBatch
+	limiter     *rateLimiter
+	throttled   time.Duration // the time waited for rate limits by the current write
+	preflight   *schemaCollector
+	schema      map[schemaKey]string // the field types of openGemini used by --coerce
+
+	timeRange         *timeRange
+	retentionPolicies map[string]*retentionPolicy // the retention policies by `database.rp`
+	provisioned       map[string]bool             // the checked databases and `database.rp` of --create-rp
 }
 
 func (c *ImportCommand) Run(config *ImportConfig) error {
@@ -149,129 +227,377 @@ func (c *ImportCommand) Run(config *ImportConfig) error {
 
 	c.cfg = config
 	c.fsm = new(ImportFileFSM)
+	if config.AdaptiveBatch {
+		c.adaptive = newAdaptiveBatch(config.BatchSize)
+	}
+	if config.MaxPointsPerSecond < 0 || config.MaxBytesPerSecond < 0 {
+		return errors.New("--max-points-per-second and --max-bytes-per-second must not be negative")
+	}
+	if config.MaxPointsPerSecond > 0 || config.MaxBytesPerSecond > 0 || config.ControlFile != "" {
+		c.limiter = newRateLimiter(config.RateLimit)
+	}
+	if config.ControlFile != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go c.watchControlFile(ctx)
+	}
+	if c.fsm.staticTags, err = parseKeyValues("add-tag", config.AddTags); err != nil {
+		return err
+	}
 	return c.process()
 }
 
 func (c *ImportCommand) process() error {
+	if err := validateOnError(c.cfg.OnError); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if err := validateRetentionCheck(c.cfg.RetentionCheck); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	var err error
+	if c.timeRange, err = parseTimeRange(c.cfg.Start, c.cfg.End); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if err = c.validateCreateRP(); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if _, err = c.parseDefaultTime(); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if err = c.validateFormat(); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if err = c.resolveTransport(); err != nil {
+		return &ExitError{Code: ExitCodeFailure, Err: err}
+	}
+	if c.cfg.Follow {
+		if c.cfg.Preflight || c.cfg.Coerce {
+			// the followed file is never complete, its schema cannot be checked before the import
+			return &ExitError{Code: ExitCodeFailure, Err: errors.New("--preflight and --coerce do not support --follow")}
+		}
+		return c.follow(context.Background())
+	}
+	var ctx = context.Background()
+	if c.cfg.Preflight || c.cfg.Coerce {
+		if err := c.preflightSchema(ctx); err != nil {
+			return err
+		}
+	}
+	return c.importFile(ctx)
+}
+
+// validateFormat check the options of the format before the import, otherwise every record
+// fails by the same invalid option
+func (c *ImportCommand) validateFormat() error {
+	switch c.cfg.Format {
+	case importFormatNDJSON:
+		_, err := newJSONMapping(c.cfg)
+		return err
+	case importFormatGraphite:
+		_, err := NewGraphiteParser(c.cfg.Templates)
+		return err
+	case importFormatParquet:
+		return c.validateParquetSchema()
+	}
+	return nil
+}
+
+// importFile read the file by the format and write it to openGemini
+func (c *ImportCommand) importFile(ctx context.Context) error {
 	file, err := os.Open(c.cfg.Path)
 	if err != nil {
 		slog.Error("open file failed", "file", c.cfg.Path, "reason", err)
-		return err
+		return &ExitError{Code: ExitCodeFailure, Err: err}
 	}
 	defer file.Close()
-	var ctx = context.Background()
 	switch c.cfg.Format {
 	case importFormatLineProtocol:
 		scanner := bufio.NewReader(file)
-		for {
+		for lineNo := 1; ; lineNo++ {
 			line, err := scanner.ReadBytes('\n')
-			if err != nil {
-				if err == io.EOF {
-					if len(line) > 0 {
-						fsmCall, err := c.fsm.processLineProtocol(string(line))
-						if err != nil {
-							slog.Error("process line protocol failed", "reason", err)
-							break
-						}
-						err = fsmCall(ctx, c)
-						if err != nil {
-							slog.Error("call line protocol fsm function failed", "reason", err)
-						}
-					}
-					break
+			if err != nil && (err != io.EOF || len(line) == 0) {
+				if err != io.EOF {
+					return c.finish(ctx, c.exitError(fmt.Errorf("read line failed: %w", err)))
 				}
-				slog.Error("read line failed", "reason", err)
-				continue
+				break
 			}
 			fsmCall, err := c.fsm.processLineProtocol(string(line))
-			if err != nil {
-				slog.Error("process line protocol failed", "reason", err)
-				continue
+			if err == nil {
+				err = fsmCall(ctx, c)
 			}
-			err = fsmCall(ctx, c)
 			if err != nil {
-				slog.Error("call line protocol fsm function failed", "reason", err)
-				continue
+				if err := c.onError(err, "process line protocol failed", "line", lineNo); err != nil {
+					return c.finish(ctx, err)
+				}
 			}
 		}
-		if err := c.fsm.clearBuffer()(ctx, c); err != nil {
-			slog.Error("clear buffer failed", "reason", err)
+		if err := c.fsm.csvSectionError(); err != nil {
+			if err := c.onError(err, "process line protocol failed"); err != nil {
+				return c.finish(ctx, err)
+			}
 		}
-		slog.Info("process finished", "path", c.cfg.Path)
-		return nil
+		return c.finish(ctx, nil)
 	case importFormatCSV:
 		slog.Info("tips: csv file import only support by column write protocol")
-		csvReader := csv.NewReader(file)
-		csvReader.Comment = '#'
+		dialect, err := newCSVDialect(c.cfg)
+		if err != nil {
+			return &ExitError{Code: ExitCodeFailure, Err: err}
+		}
+		csvReader := NewCSVReader(file, dialect)
+		if err := csvReader.SkipRows(c.cfg.SkipRows); err != nil && err != io.EOF {
+			return c.exitError(err)
+		}
+		if c.cfg.NoHeader { // the header is given by --columns
+			if len(c.cfg.Columns) == 0 {
+				return &ExitError{Code: ExitCodeFailure, Err: errors.New("--columns is required when --no-header is specified")}
+			}
+			fsmCall, _ := c.fsm.processCSV(c.cfg.Columns)
+			if err := fsmCall(ctx, c); err != nil {
+				c.stats.record(err)
+				slog.Error("call csv header fsm function failed", "reason", err)
+				return c.exitError(err)
+			}
+		}
 		for {
 			row, err := csvReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err == nil {
+				var fsmCall FSMCall
+				if fsmCall, err = c.fsm.processCSV(row); err == nil {
+					err = fsmCall(ctx, c)
+				}
+			}
+			if err != nil {
+				if err := c.onError(err, "process csv line failed", "line", csvReader.Line()); err != nil {
+					return c.finish(ctx, err)
+				}
+			}
+		}
+		return c.finish(ctx, nil)
+	case importFormatAnnotatedCSV:
+		dialect, err := newCSVDialect(c.cfg)
+		if err != nil {
+			return &ExitError{Code: ExitCodeFailure, Err: err}
+		}
+		dialect.Comment = 0 // annotations start with #
+		csvReader := NewCSVReader(file, dialect)
+		csvReader.keepBlankLines = true
+		for {
+			row, err := csvReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err == nil {
+				var fsmCall FSMCall
+				if fsmCall, err = c.fsm.processAnnotatedCSV(row); err == nil {
+					err = fsmCall(ctx, c)
+				}
+			}
+			if err != nil {
+				if err := c.onError(err, "process annotated csv line failed", "line", csvReader.Line()); err != nil {
+					return c.finish(ctx, err)
+				}
+			}
+		}
+		return c.finish(ctx, nil)
+	case importFormatPromText:
+		reader := bufio.NewReader(file)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				if err != io.EOF {
+					return c.finish(ctx, c.exitError(fmt.Errorf("read prom text line failed: %w", err)))
+				}
+				break
+			}
+			fsmCall, err := c.fsm.processPromText(line)
+			if err == nil {
+				err = fsmCall(ctx, c)
+			}
 			if err != nil {
-				if err == io.EOF {
+				if err := c.onError(err, "process prom text line failed", "line", strings.TrimSpace(line)); err != nil {
+					return c.finish(ctx, err)
+				}
+			}
+		}
+		return c.finish(ctx, nil)
+	case importFormatOpenTSDB:
+		reader := bufio.NewReader(file)
+		if first, err := peekNonSpace(reader); err == nil && (first == '[' || first == '{') { // http api json
+			docReader, err := NewJSONDocumentReader(reader)
+			if err != nil {
+				return c.exitError(err)
+			}
+			for {
+				doc, pos, err := docReader.Read()
+				var docErr *jsonDocumentError
+				if err != nil && !errors.As(err, &docErr) {
+					if err != io.EOF {
+						return c.finish(ctx, c.exitError(fmt.Errorf("read opentsdb json failed: %w", err)))
+					}
 					break
 				}
-				slog.Error("read csv line failed", "reason", err)
-				continue
+				if err == nil {
+					var dps []*OpenTSDBDataPoint
+					if dps, err = parseOpenTSDBJSON(doc); err == nil {
+						fsmCall, _ := c.fsm.processOpenTSDB(dps)
+						err = fsmCall(ctx, c)
+					}
+				}
+				if err != nil {
+					if err := c.onError(err, "process opentsdb json failed", "position", pos); err != nil {
+						return c.finish(ctx, err)
+					}
+				}
+			}
+		} else {
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil && (err != io.EOF || line == "") {
+					if err != io.EOF {
+						return c.finish(ctx, c.exitError(fmt.Errorf("read opentsdb line failed: %w", err)))
+					}
+					break
+				}
+				if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				dp, err := parseOpenTSDBTelnet(line)
+				if err == nil {
+					fsmCall, _ := c.fsm.processOpenTSDB([]*OpenTSDBDataPoint{dp})
+					err = fsmCall(ctx, c)
+				}
+				if err != nil {
+					if err := c.onError(err, "process opentsdb line failed", "line", line); err != nil {
+						return c.finish(ctx, err)
+					}
+				}
+			}
+		}
+		return c.finish(ctx, nil)
+	case importFormatGraphite:
+		reader := bufio.NewReader(file)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				if err != io.EOF {
+					return c.finish(ctx, c.exitError(fmt.Errorf("read graphite line failed: %w", err)))
+				}
+				break
+			}
+			fsmCall, err := c.fsm.processGraphite(line)
+			if err == nil {
+				err = fsmCall(ctx, c)
 			}
-			fsmCall, err := c.fsm.processCSV(row)
 			if err != nil {
-				slog.Error("process csv line failed", "reason", err)
-				continue
+				if err := c.onError(err, "process graphite line failed", "line", strings.TrimSpace(line)); err != nil {
+					return c.finish(ctx, err)
+				}
 			}
-			err = fsmCall(ctx, c)
+		}
+		return c.finish(ctx, nil)
+	case importFormatParquet:
+		stat, err := file.Stat()
+		if err != nil {
+			return &ExitError{Code: ExitCodeFailure, Err: err}
+		}
+		reader, err := NewParquetReader(file, stat.Size())
+		if err != nil {
+			slog.Error("open parquet file failed", "file", c.cfg.Path, "reason", err)
+			return &ExitError{Code: ExitCodeFailure, Err: err}
+		}
+		header := reader.Columns()
+		for {
+			row, err := reader.Read()
 			if err != nil {
-				slog.Error("call csv line fsm function failed", "reason", err)
-				continue
+				if err != io.EOF {
+					return c.finish(ctx, c.exitError(fmt.Errorf("read parquet row %d failed: %w", reader.Line(), err)))
+				}
+				break
+			}
+			fsmCall, _ := c.fsm.processParquet(header, row)
+			if err = fsmCall(ctx, c); err != nil {
+				if err := c.onError(err, "process parquet row failed", "row", reader.Line()); err != nil {
+					return c.finish(ctx, err)
+				}
 			}
 		}
-		if err := c.fsm.clearBuffer()(ctx, c); err != nil {
-			slog.Error("clear buffer failed", "reason", err)
+		return c.finish(ctx, nil)
+	case importFormatNDJSON:
+		reader, err := NewJSONDocumentReader(file)
+		if err != nil {
+			return c.exitError(err)
 		}
-		slog.Info("process finished", "path", c.cfg.Path)
-		return nil
+		for {
+			doc, pos, err := reader.Read()
+			var docErr *jsonDocumentError
+			if err != nil && !errors.As(err, &docErr) {
+				if err != io.EOF {
+					return c.finish(ctx, c.exitError(fmt.Errorf("read json document failed: %w", err)))
+				}
+				break
+			}
+			if err == nil {
+				var fsmCall FSMCall
+				if fsmCall, err = c.fsm.processNDJSON(doc); err == nil {
+					err = fsmCall(ctx, c)
+				}
+			}
+			if err != nil {
+				if err := c.onError(err, "process json document failed", "position", pos); err != nil {
+					return c.finish(ctx, err)
+				}
+			}
+		}
+		return c.finish(ctx, nil)
 	// support jsonProm
 	case importFormatJSONProm:
-		slog.Info("tips: prom json file import only support by row write protocol")
-		dec := json.NewDecoder(file)
-		for dec.More() {
-			fsmCall, err := c.fsm.processJsonP(dec)
-			if err != nil {
-				slog.Error("process prom json line failed", "reason", err)
-				continue
+		err := NewPromQueryDecoder(file).Decode(func(resultType string, series *JsonPResult) error {
+			fsmCall, err := c.fsm.processJsonP(resultType, series)
+			if err == nil {
+				err = fsmCall(ctx, c)
 			}
-			err = fsmCall(ctx, c)
 			if err != nil {
-				slog.Error("call prom json fsm function failed", "reason", err)
-				continue
+				return c.onError(err, "process prom json series failed", "metric", series.Metric)
 			}
+			return nil
+		})
+		if err != nil {
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				return c.finish(ctx, err)
+			}
+			return c.finish(ctx, c.onError(err, "process prom json failed"))
 		}
-		if err := c.fsm.clearBuffer()(ctx, c); err != nil {
-			slog.Error("clear buffer failed", "reason", err)
-		}
-		slog.Info("process finished", "path", c.cfg.Path)
-		return nil
+		return c.finish(ctx, nil)
 	// support jsonInflux
 	case importFormatJSONInflux:
-		slog.Info("tips: influx json file import only support by row write protocol")
-		dec := json.NewDecoder(file)
-		for dec.More() {
-			fsmCall, err := c.fsm.processJsonI(dec)
-			if err != nil {
-				slog.Error("process influx json line failed", "reason", err)
-				continue
+		c.fsm.influxFloats = influxFloatFields(file, c.cfg.Measurement)
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return c.finish(ctx, c.exitError(fmt.Errorf("read file failed: %w", err)))
+		}
+		err := NewInfluxQueryDecoder(file).Decode(func(series *JsonIResult) error {
+			fsmCall, err := c.fsm.processJsonI(series)
+			if err == nil {
+				err = fsmCall(ctx, c)
 			}
-			err = fsmCall(ctx, c)
 			if err != nil {
-				slog.Error("call influx json fsm function failed", "reason", err)
-				continue
+				return c.onError(err, "process influx json series failed", "name", series.Measurement)
 			}
+			return nil
+		})
+		if err != nil {
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				return c.finish(ctx, err)
+			}
+			return c.finish(ctx, c.onError(err, "process influx json failed"))
 		}
-		if err := c.fsm.clearBuffer()(ctx, c); err != nil {
-			slog.Error("clear buffer failed", "reason", err)
-		}
-		slog.Info("process finished", "path", c.cfg.Path)
-		return nil
+		return c.finish(ctx, nil)
 	default:
-		return fmt.Errorf("unknown --format %s, only support line_protocol, csv", c.cfg.Format)
+		return &ExitError{Code: ExitCodeFailure, Err: fmt.Errorf("unknown --format %s, only support line_protocol, csv, annotated_csv, jsoni, jsonp, prom_text, ndjson, opentsdb, graphite, parquet", c.cfg.Format)}
 	}
 }
 
@@ -290,8 +616,20 @@ type ImportFileFSM struct {
 	tagMap           map[string]FieldPos
 	fieldMap         map[string]FieldPos
 	timeField        FieldPos
+	measurementField FieldPos
+	staticTags       map[string]string
+	annotatedTable   *annotatedCSVTable
+	pivotPoints      map[string]*opengemini.Point
+	promTypes        map[string]string
+	influxFloats     map[string]map[string]bool // the number fields of jsoni measurements which are float
+	defaultTime      int64
+	jsonMapping      *JSONMapping
+	graphiteParser   *GraphiteParser
+	csvSection       *csvSection
 	batchLPBuffer    []string
 	batchPointBuffer []*opengemini.Point
+	batchBytes       int
+	lastFlush        time.Time
 }
 
 type FieldPos struct {
@@ -312,7 +650,7 @@ func (fsm *ImportFileFSM) clearBuffer() FSMCall {
 			errs = errors.Join(errs, err)
 		}
 
-		if len(fsm.batchPointBuffer) != 0 { // only support column write
+		if len(fsm.batchPointBuffer) != 0 {
 			err := command.executeByPointBuffer(ctx)
 			errs = errors.Join(errs, err)
 		}
@@ -321,14 +659,24 @@ func (fsm *ImportFileFSM) clearBuffer() FSMCall {
 }
 
 func (fsm *ImportFileFSM) processLineProtocol(data string) (FSMCall, error) {
+	if fsm.csvSection != nil && fsm.csvSection.pending != "" { // the line break of quoted cell
+		return fsm.processCSVSection(data), nil
+	}
 	if strings.HasPrefix(data, importTokenDDL) {
 		fsm.state = importStateDDL
 		return FSMCallEmpty, nil
 	}
 	if strings.HasPrefix(data, importTokenDML) {
 		fsm.state = importStateDML
-		fsm.retentionPolicy = "autogen"
-		return FSMCallEmpty, nil
+		fsm.csvSection = nil
+		return func(ctx context.Context, command *ImportCommand) error {
+			// --retention-policy until `# CONTEXT-RETENTION-POLICY:` changes it
+			var retentionPolicy = command.cfg.RetentionPolicy
+			if retentionPolicy == "" {
+				retentionPolicy = common.DefaultRetentionPolicy // "autogen"
+			}
+			return command.switchTarget(ctx, fsm.database, retentionPolicy)
+		}, nil
 	}
 	switch fsm.state {
 	case importStateDDL:
@@ -337,23 +685,23 @@ func (fsm *ImportFileFSM) processLineProtocol(data string) (FSMCall, error) {
 			return FSMCallEmpty, nil
 		}
 		return func(ctx context.Context, command *ImportCommand) error {
-			_, err := command.httpClient.Query(ctx, &opengemini.Query{
-				Command: data, // CREATE DATABASE NOAA_water_database
-			})
-			if err != nil {
-				slog.Error("execute ddl failed", "reason", err, "command", data)
-				return err
-			}
-			slog.Info("execute ddl success", "command", data)
-			return nil
+			return command.executeDDL(ctx, data) // CREATE DATABASE NOAA_water_database
 		}, nil
 	case importStateDML:
 		if strings.HasPrefix(data, importTokenDatabase) {
-			fsm.database = strings.TrimSpace(strings.Split(data, ":")[1])
-			return FSMCallEmpty, nil
+			var database = strings.TrimSpace(strings.Split(data, ":")[1])
+			return func(ctx context.Context, command *ImportCommand) error {
+				err := command.switchTarget(ctx, database, fsm.retentionPolicy)
+				return errors.Join(err, command.ensureRetentionPolicy(ctx, database))
+			}, nil
 		}
 		if strings.HasPrefix(data, importTokenRetentionPolicy) {
-			fsm.retentionPolicy = strings.TrimSpace(strings.Split(data, ":")[1])
+			var retentionPolicy = strings.TrimSpace(strings.Split(data, ":")[1])
+			return func(ctx context.Context, command *ImportCommand) error {
+				return command.switchTarget(ctx, fsm.database, retentionPolicy)
+			}, nil
+		}
+		if fsm.parseContextDirective(data) {
 			return FSMCallEmpty, nil
 		}
 		if strings.HasPrefix(data, "#") { // skip line with prefix #
@@ -363,22 +711,33 @@ func (fsm *ImportFileFSM) processLineProtocol(data string) (FSMCall, error) {
 		if strings.TrimSpace(data) == "" {
 			return FSMCallEmpty, nil
 		}
+		if fsm.csvSection != nil {
+			return fsm.processCSVSection(data), nil
+		}
 		data = strings.TrimSpace(data)
 		return func(ctx context.Context, command *ImportCommand) error {
 			if command.fsm.database == "" {
 				return errors.New("database is required, make sure `# CONTEXT-DATABASE:` token is exist")
 			}
 
-			command.fsm.batchLPBuffer = append(command.fsm.batchLPBuffer, data)
-			if len(command.fsm.batchLPBuffer) < command.cfg.BatchSize {
-				return nil
-			}
-			return command.excuteByLPBuffer(ctx)
+			return command.appendLine(ctx, data)
 		}, nil
 	}
 	return FSMCallEmpty, nil
 }
 
+// switchTarget change the database and retention policy of the following lines, the buffered
+// lines and points are written to the current ones before they are changed
+func (c *ImportCommand) switchTarget(ctx context.Context, database, retentionPolicy string) error {
+	var err error
+	if database != c.fsm.database || retentionPolicy != c.fsm.retentionPolicy {
+		err = c.fsm.clearBuffer()(ctx, c)
+	}
+	c.fsm.database = database
+	c.fsm.retentionPolicy = retentionPolicy
+	return err
+}
+
 func (fsm *ImportFileFSM) processCSV(data []string) (FSMCall, error) {
 	if len(data) == 0 {
 		return FSMCallEmpty, nil
@@ -388,75 +747,16 @@ func (fsm *ImportFileFSM) processCSV(data []string) (FSMCall, error) {
 	case importStateDDL: // line 1 is the csv header
 		fsm.state = importStateDML
 		return func(ctx context.Context, command *ImportCommand) error {
-			cmdStr := fmt.Sprintf("CREATE DATABASE %s", command.cfg.Database)
-			_, err := command.httpClient.Query(ctx, &opengemini.Query{
-				Command: cmdStr, // CREATE DATABASE NOAA_water_database
-			})
-			if err != nil {
-				slog.Error("execute ddl failed", "reason", err, "command", cmdStr)
+			if err := command.createDatabase(ctx); err != nil {
 				return err
 			}
-			slog.Info("execute ddl success", "command", cmdStr)
 
 			command.cfg.ColumnWrite = true // only support
 			fsm.database = command.cfg.Database
 			fsm.retentionPolicy = command.cfg.RetentionPolicy
 			fsm.measurement = command.cfg.Measurement
-			fsm.tagMap = make(map[string]FieldPos)
-			fsm.fieldMap = make(map[string]FieldPos)
-			for _, tag := range command.cfg.Tags { // tags
-				fsm.tagMap[tag] = FieldPos{}
-			}
-
-			for _, field := range command.cfg.Fields { // fields
-				fsm.fieldMap[field] = FieldPos{}
-			}
-			if len(data) > 0 {
-				data[0] = strings.TrimPrefix(data[0], "\ufeff") // jump BOM
-			}
-
-			for idx, datum := range data { // column name
-				_, ok := fsm.tagMap[datum]
-				if ok {
-					fsm.tagMap[datum] = FieldPos{datum, idx}
-					continue
-				}
-				_, ok = fsm.fieldMap[datum]
-				if ok {
-					fsm.fieldMap[datum] = FieldPos{datum, idx}
-					continue
-				}
-				if command.cfg.TimeField == datum {
-					fsm.timeField = FieldPos{datum, idx}
-					continue
-				}
-
-				if len(command.cfg.Fields) == 0 { // If --field is not specified, the remaining column are fields.
-					fsm.fieldMap[datum] = FieldPos{datum, idx}
-				} else {
-					slog.Info("ignore column name", "column", datum)
-				}
-			}
-			for _, field := range command.cfg.Fields {
-				if fsm.fieldMap[field].Name == "" {
-					return fmt.Errorf("field name (%s) not in csv header", field)
-				}
-				if _, exsit := fsm.tagMap[field]; exsit {
-					return errors.New(field + " is in both tags and fields")
-				}
-			}
-
-			for _, tag := range command.cfg.Tags {
-				if fsm.tagMap[tag].Name == "" {
-					return fmt.Errorf("tag name (%s) not in csv header", tag)
-				}
-				if _, exist := fsm.fieldMap[tag]; exist {
-					return errors.New(tag + " is in both tags and fields")
-				}
-			}
-
-			if fsm.timeField.Name == "" {
-				return errors.New("time name not in csv header " + command.cfg.TimeField)
+			if err := fsm.parseCSVHeader(command.cfg, data); err != nil {
+				return err
 			}
 			slog.Info("parse header success")
 			return nil
@@ -469,166 +769,219 @@ func (fsm *ImportFileFSM) processCSV(data []string) (FSMCall, error) {
 			if command.fsm.retentionPolicy == "" {
 				command.fsm.retentionPolicy = common.DefaultRetentionPolicy // "autogen"
 			}
-			if command.cfg.Measurement == "" {
-				return errors.New("measurement is required")
-			}
-			if len(fsm.fieldMap) == 0 {
-				return errors.New("field is required")
+			point, err := fsm.csvPoint(command, data)
+			if err != nil {
+				return err
 			}
+			return command.appendPoint(ctx, point)
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
 
-			var point = &opengemini.Point{
-				Measurement: command.cfg.Measurement,
-				Timestamp:   command.parseTimestamp2Int64(data[fsm.timeField.Pos]),
-				Tags:        make(map[string]string),
-				Fields:      make(map[string]interface{}),
-			}
-			for _, tag := range fsm.tagMap {
-				point.Tags[tag.Name] = data[tag.Pos]
-			}
-			for _, field := range fsm.fieldMap {
-				point.Fields[field.Name] = data[field.Pos]
-			}
+// csvPoint build the point of csv row by the column mapping of header
+func (fsm *ImportFileFSM) csvPoint(command *ImportCommand, data []string) (*opengemini.Point, error) {
+	measurement := fsm.csvRowMeasurement(data)
+	if measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+	if len(fsm.fieldMap) == 0 {
+		return nil, errors.New("field is required")
+	}
 
-			command.fsm.batchPointBuffer = append(command.fsm.batchPointBuffer, point)
+	timestamp, ok := csvCell(data, fsm.timeField.Pos, command.cfg.NullValue)
+	if !ok {
+		return nil, errors.New("time column is missing or null")
+	}
 
-			if len(command.fsm.batchPointBuffer) < command.cfg.BatchSize { // continue collect data
-				return nil
-			}
-			// consumption data, if data is full
-			return command.executeByPointBuffer(ctx)
-		}, nil
+	var point = &opengemini.Point{
+		Measurement: measurement,
+		Timestamp:   command.parseTimestamp2Int64(timestamp),
+		Tags:        make(map[string]string),
+		Fields:      make(map[string]interface{}),
 	}
-	return FSMCallEmpty, nil
+	for key, value := range fsm.staticTags {
+		point.Tags[key] = value
+	}
+	for _, tag := range fsm.tagMap {
+		if value, ok := csvCell(data, tag.Pos, command.cfg.NullValue); ok {
+			point.Tags[tag.Name] = value
+		}
+	}
+	for _, field := range fsm.fieldMap {
+		if value, ok := csvCell(data, field.Pos, command.cfg.NullValue); ok {
+			point.Fields[field.Name] = value
+		}
+	}
+	if len(point.Fields) == 0 {
+		return nil, errors.New("all fields of the row are null")
+	}
+	return point, nil
+}
+
+// appendPoint append the point to batch buffer, the buffer is written if it is full
+func (c *ImportCommand) appendPoint(ctx context.Context, point *opengemini.Point) error {
+	if !c.filterTime(ctx, point.Timestamp) {
+		return nil
+	}
+	if err := c.coercePoint(point); err != nil {
+		return err
+	}
+	c.fsm.batchPointBuffer = append(c.fsm.batchPointBuffer, point)
+	c.fsm.batchBytes += c.pointSize(point)
+	if !c.batchFull(len(c.fsm.batchPointBuffer)) { // continue collect data
+		return nil
+	}
+	return c.executeByPointBuffer(ctx)
 }
 
+// seriesKey returns the unique key of the point series and time, tags are sorted
+func seriesKey(point *opengemini.Point) string {
+	return seriesName(point) + " " + strconv.FormatInt(point.Timestamp, 10)
+}
+
+// seriesName returns the measurement and the sorted tags of the point
+func seriesName(point *opengemini.Point) string {
+	var builder strings.Builder
+	builder.WriteString(point.Measurement)
+	for _, key := range core.SortedKeys(point.Tags) {
+		builder.WriteString("," + key + "=" + point.Tags[key])
+	}
+	return builder.String()
+}
+
+// appendPivotPoint merge the fields into the buffered point with the same series and time,
+// so that the field/value rows are pivoted into one point.
+func (c *ImportCommand) appendPivotPoint(ctx context.Context, point *opengemini.Point) error {
+	if !c.filterTime(ctx, point.Timestamp) {
+		return nil
+	}
+	if err := c.coercePoint(point); err != nil {
+		return err
+	}
+	if c.fsm.pivotPoints == nil {
+		c.fsm.pivotPoints = make(map[string]*opengemini.Point)
+	}
+	key := seriesKey(point)
+	if exist, ok := c.fsm.pivotPoints[key]; ok {
+		for name, value := range point.Fields {
+			exist.Fields[name] = value
+		}
+		return nil
+	}
+	c.fsm.pivotPoints[key] = point
+	c.fsm.batchPointBuffer = append(c.fsm.batchPointBuffer, point)
+	c.fsm.batchBytes += c.pointSize(point)
+	if !c.batchFull(len(c.fsm.batchPointBuffer)) { // continue collect data
+		return nil
+	}
+	return c.executeByPointBuffer(ctx)
+}
+
+// excuteByLPBuffer write the buffered line protocol by http as it is
 func (c *ImportCommand) excuteByLPBuffer(ctx context.Context) error {
-	var err error
+	var count = min(c.batchSize(), len(c.fsm.batchLPBuffer))
+	var batch = c.fsm.batchLPBuffer[:count]
 	defer func() {
-		if len(c.fsm.batchLPBuffer) <= c.cfg.BatchSize {
-			c.fsm.batchLPBuffer = c.fsm.batchLPBuffer[:0]
-		} else { // more than one batch
-			c.fsm.batchLPBuffer = c.fsm.batchLPBuffer[c.cfg.BatchSize:]
+		c.fsm.batchLPBuffer = append(c.fsm.batchLPBuffer[:0], c.fsm.batchLPBuffer[count:]...)
+		var bytes int
+		for _, line := range c.fsm.batchLPBuffer {
+			bytes += len(line) + 1
 		}
+		c.resetBatch(bytes)
 	}()
-	var lines = strings.Join(c.fsm.batchLPBuffer[:min(c.cfg.BatchSize, len(c.fsm.batchLPBuffer))], "\n")
-	fmt.Println("---", lines)
-	if c.cfg.ColumnWrite {
-		var builderName = c.fsm.database + "." + c.fsm.retentionPolicy
-		builder, ok := builderEntities[builderName]
-		if !ok {
-			builder, err = opengemini.NewWriteRequestBuilder(c.fsm.database, c.fsm.retentionPolicy)
-			if err != nil {
-				return err
-			}
-			builderEntities[builderName] = builder
+	var lines = strings.Join(batch, "\n")
+	return c.write(ctx, count, func(ctx context.Context, lo, hi int) error {
+		var raw = lines
+		if lo != 0 || hi != count { // the batch is split
+			raw = strings.Join(batch[lo:hi], "\n")
 		}
-		parser := core.NewLineProtocolParser(lines)
-		points, err := parser.Parse(c.cfg.TimeMultiplier)
+		if err := c.throttle(ctx, hi-lo, len(raw)); err != nil {
+			return err
+		}
+		return c.httpClient.Write(ctx, c.fsm.database, c.fsm.retentionPolicy, raw, c.cfg.Precision)
+	})
+}
+
+func (c *ImportCommand) executeByPointBuffer(ctx context.Context) error {
+	defer func() {
+		c.fsm.batchPointBuffer = c.fsm.batchPointBuffer[:0]
+		clear(c.fsm.pivotPoints)
+		c.resetBatch(0)
+	}()
+	return c.writePoints(ctx, c.fsm.batchPointBuffer)
+}
+
+// writePoints write the points by line protocol or column write protocol, the points are encoded
+// once and encoded again only if the batch is split.
+func (c *ImportCommand) writePoints(ctx context.Context, points []*opengemini.Point) error {
+	if c.preflight != nil {
+		c.preflight.collect(c.fsm.database, points)
+		return nil
+	}
+	if c.cfg.Transport == importTransportPromRemote {
+		return c.writePromRemote(ctx, points)
+	}
+	if !c.cfg.ColumnWrite {
+		lines, err := core.EncodeLineProtocol(points)
 		if err != nil {
 			return err
 		}
-		var recordBuilder = make(map[string]opengemini.RecordBuilder)
-		var recordLines []opengemini.RecordLine
-		for _, point := range points {
-			rb, ok := recordBuilder[point.Measurement]
-			if !ok {
-				rb, err = opengemini.NewRecordBuilder(point.Measurement)
-				if err != nil {
+		return c.write(ctx, len(points), func(ctx context.Context, lo, hi int) error {
+			var raw = lines
+			if lo != 0 || hi != len(points) {
+				if raw, err = core.EncodeLineProtocol(points[lo:hi]); err != nil {
 					return err
 				}
-				recordBuilder[point.Measurement] = rb
 			}
-			newLine := rb.NewLine()
-			for key, value := range point.Tags {
-				newLine.AddTag(key, value)
+			if err := c.throttle(ctx, hi-lo, len(raw)); err != nil {
+				return err
 			}
-			for key, value := range point.Fields {
-				newLine.AddField(key, value)
+			// the timestamp of point is always nanosecond
+			return c.httpClient.Write(ctx, c.fsm.database, c.fsm.retentionPolicy, raw, "ns")
+		})
+	}
+	if c.encoder == nil {
+		c.encoder = newColumnEncoder(c.cfg.Username, c.cfg.Password)
+	}
+	sortByMeasurement(points) // the failed measurement of partial write is isolated quickly
+	request, err := c.encoder.encode(c.fsm.database, c.fsm.retentionPolicy, points)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, len(points), func(ctx context.Context, lo, hi int) error {
+		var req = request
+		if lo != 0 || hi != len(points) {
+			if req, err = c.encoder.encode(c.fsm.database, c.fsm.retentionPolicy, points[lo:hi]); err != nil {
+				return err
 			}
-			recordLines = append(recordLines, newLine.Build(point.Timestamp))
 		}
-		request, err := builder.Authenticate(c.cfg.Username, c.cfg.Password).AddRecord(recordLines...).Build()
-		if err != nil {
+		if err := c.throttle(ctx, hi-lo, protobuf.Size(req)); err != nil {
 			return err
 		}
-		response, err := c.writeClient.Write(ctx, request)
+		response, err := c.writeClient.Write(ctx, req)
 		if err != nil {
 			return err
 		}
-		switch response.Code {
-		case 0:
-			return nil
-		case 1:
-			return fmt.Errorf("write failed, code: %d, partial write failure", response.GetCode())
-		case 2:
-			return fmt.Errorf("write failed, code: %d, write failure", response.GetCode())
-		default:
-			return fmt.Errorf("unexpected response code: %d", response.Code)
+		// the partially written batch is split until the failed points are isolated
+		if err = checkWriteResponse(response); errors.Is(err, errWriteRejected) && (hi-lo == 1 || !errors.Is(err, errPartialWrite)) {
+			c.rejectPoints(points[lo:hi], err)
 		}
-	} else {
-		err = c.httpClient.Write(ctx, c.fsm.database, c.fsm.retentionPolicy, lines, c.cfg.Precision)
-	}
-	return err
+		return err
+	})
 }
 
-func (c *ImportCommand) executeByPointBuffer(ctx context.Context) error {
-	var err error
-	defer func() {
-		c.fsm.batchPointBuffer = c.fsm.batchPointBuffer[:0]
-	}()
-	var builderName = c.fsm.database + "." + c.fsm.retentionPolicy
-	builder, ok := builderEntities[builderName]
-	if !ok {
-		var buildRequestErr error
-		builder, buildRequestErr = opengemini.NewWriteRequestBuilder(c.fsm.database, c.fsm.retentionPolicy)
-		if buildRequestErr != nil {
-			err = errors.Join(err, buildRequestErr)
-			return err
-		}
-		builderEntities[builderName] = builder
-	}
-	var recordBuilder = make(map[string]opengemini.RecordBuilder)
-	var recordLines []opengemini.RecordLine
-	for _, point := range c.fsm.batchPointBuffer {
-		rb, ok := recordBuilder[point.Measurement]
-		if !ok {
-			var recordBuilderErr error
-			rb, recordBuilderErr = opengemini.NewRecordBuilder(point.Measurement)
-			if recordBuilderErr != nil {
-				err = errors.Join(err, recordBuilderErr)
-				return err
-			}
-			recordBuilder[point.Measurement] = rb
-		}
-		newLine := rb.NewLine()
-		for key, value := range point.Tags {
-			newLine.AddTag(key, value)
-		}
-		for key, value := range point.Fields {
-			newLine.AddField(key, value)
-		}
-		recordLines = append(recordLines, newLine.Build(point.Timestamp))
-	}
-	var buildErr error
-	request, buildErr := builder.Authenticate(c.cfg.Username, c.cfg.Password).AddRecord(recordLines...).Build()
-	if buildErr != nil {
-		err = errors.Join(err, buildErr)
-		return err
-	}
-	response, writeErr := c.writeClient.Write(ctx, request)
-	if writeErr != nil {
-		err = errors.Join(err, writeErr)
-		return err
-	}
+// checkWriteResponse convert the response code of column write to error
+func checkWriteResponse(response *proto.WriteResponse) error {
 	switch response.Code {
-	case 0:
-		return err
-	case 1:
-		return fmt.Errorf("%w\nwrite failed, code: %d, partial write failure", err, response.GetCode())
-	case 2:
-		return fmt.Errorf("%w\nwrite failed, code: %d, write failure", err, response.GetCode())
+	case proto.ResponseCode_Success:
+		return nil
+	case proto.ResponseCode_Partial:
+		return fmt.Errorf("%w, code: %d", errPartialWrite, response.GetCode())
+	case proto.ResponseCode_Failed:
+		return fmt.Errorf("%w, code: %d, write failure", errWriteRejected, response.GetCode())
 	default:
-		return fmt.Errorf("%w\nunexpected response code: %d", err, response.Code)
+		return fmt.Errorf("unexpected response code: %d", response.Code)
 	}
 }
 
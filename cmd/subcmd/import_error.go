@@ -0,0 +1,310 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	importOnErrorAbort = "abort"
+	importOnErrorSkip  = "skip"
+	importOnErrorRetry = "retry"
+
+	importMaxRetries = 3
+)
+
+// the exit codes of import, the most severe failure decides the exit code
+const (
+	ExitCodeSuccess        = 0
+	ExitCodeFailure        = 1 // invalid arguments or unreadable file
+	ExitCodeParseError     = 2 // some lines are skipped because they can not be parsed
+	ExitCodeConnection     = 3 // openGemini is unreachable
+	ExitCodePartial        = 4 // some batches are rejected by openGemini
+	ExitCodeDDLError       = 5 // the statements of `# DDL` section failed
+	ExitCodeSchemaConflict = 6 // the field types of input conflict with openGemini
+)
+
+// importRetryBackoff the first backoff of --on-error=retry, it is doubled on every retry
+var importRetryBackoff = time.Second
+
+// errWriteRejected the column write request is rejected by openGemini
+var errWriteRejected = errors.New("write failed")
+
+// errPartialWrite some records of the column write request are rejected by openGemini
+var errPartialWrite = fmt.Errorf("%w, partial write", errWriteRejected)
+
+// ExitError is the error carrying the process exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// importDDLError is the failure of the statements of `# DDL` section and the creation of database
+type importDDLError struct {
+	command string
+	err     error
+}
+
+func (e *importDDLError) Error() string {
+	return fmt.Sprintf("execute ddl %q failed: %s", e.command, e.err)
+}
+
+func (e *importDDLError) Unwrap() error {
+	return e.err
+}
+
+// importWriteError is the failure of writing a batch
+type importWriteError struct {
+	lines int
+	err   error
+}
+
+func (e *importWriteError) Error() string {
+	return fmt.Sprintf("write %d lines failed: %s", e.lines, e.err)
+}
+
+func (e *importWriteError) Unwrap() error {
+	return e.err
+}
+
+// rejected returns true if the batch reached openGemini but was refused
+func (e *importWriteError) rejected() bool {
+	var writeErr *core.WriteError
+	if errors.As(e.err, &writeErr) || errors.Is(e.err, errWriteRejected) {
+		return true
+	}
+	if st, ok := status.FromError(e.err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.Unknown:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// retryable returns true if the batch may succeed by writing it again
+func (e *importWriteError) retryable() bool {
+	var writeErr *core.WriteError
+	if errors.As(e.err, &writeErr) {
+		return writeErr.Retryable() && !e.tooLarge()
+	}
+	return !e.rejected()
+}
+
+// tooLarge returns true if the batch exceeds the request size limit of openGemini
+func (e *importWriteError) tooLarge() bool {
+	var writeErr *core.WriteError
+	if errors.As(e.err, &writeErr) {
+		return writeErr.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	st, ok := status.FromError(e.err)
+	return ok && st.Code() == codes.ResourceExhausted
+}
+
+// importStats counts the result of import
+type importStats struct {
+	written          int
+	parseErrors      int
+	ddlErrors        int
+	connectionErrors int
+	rejectedErrors   int
+	filtered         int // the points out of --start and --end
+	expired          int // the points out of the retention policy duration
+	skipped          int // the samples of NaN or Inf value
+	rejectedPoints   int
+	failures         map[string]int // the rejected points by measurement
+}
+
+func (s *importStats) errors() int {
+	return s.parseErrors + s.ddlErrors + s.connectionErrors + s.rejectedErrors
+}
+
+// exitCode returns the code of the most severe failure
+func (s *importStats) exitCode() int {
+	switch {
+	case s.connectionErrors > 0:
+		return ExitCodeConnection
+	case s.ddlErrors > 0:
+		return ExitCodeDDLError
+	case s.rejectedErrors > 0:
+		return ExitCodePartial
+	case s.parseErrors > 0:
+		return ExitCodeParseError
+	}
+	return ExitCodeSuccess
+}
+
+// record count the failure by its kind
+func (s *importStats) record(err error) {
+	var ddlErr *importDDLError
+	var writeErr *importWriteError
+	switch {
+	case errors.As(err, &ddlErr):
+		s.ddlErrors++
+	case errors.As(err, &writeErr) && writeErr.rejected():
+		s.rejectedErrors++
+	case errors.As(err, &writeErr):
+		s.connectionErrors++
+	default:
+		s.parseErrors++
+	}
+}
+
+// onError log and count the failure, it returns non-nil error if the import should be aborted
+// by --on-error=abort or --max-errors.
+func (c *ImportCommand) onError(err error, msg string, args ...any) error {
+	if c.preflight != nil { // the errors are reported by the import
+		return nil
+	}
+	slog.Error(msg, append(args, "reason", err)...)
+	c.stats.record(err)
+	if c.cfg.OnError == importOnErrorAbort {
+		return c.exitError(fmt.Errorf("import aborted: %w", err))
+	}
+	if c.cfg.MaxErrors > 0 && c.stats.errors() >= c.cfg.MaxErrors {
+		return c.exitError(fmt.Errorf("import aborted, reached --max-errors %d: %w", c.cfg.MaxErrors, err))
+	}
+	return nil
+}
+
+// exitError returns the error with the exit code of the failures so far
+func (c *ImportCommand) exitError(err error) error {
+	code := c.stats.exitCode()
+	if code == ExitCodeSuccess {
+		code = ExitCodeFailure
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// finish flush the buffer and returns the summary of import, cause is the error aborted the import
+func (c *ImportCommand) finish(ctx context.Context, cause error) error {
+	if c.preflight != nil {
+		_ = c.fsm.clearBuffer()(ctx, c)
+		return cause
+	}
+	if err := c.fsm.clearBuffer()(ctx, c); err != nil {
+		slog.Error("clear buffer failed", "reason", err)
+		c.stats.record(err)
+	}
+	slog.Info("process finished", "path", c.cfg.Path, "written", c.stats.written, "parse_errors", c.stats.parseErrors,
+		"ddl_errors", c.stats.ddlErrors, "connection_errors", c.stats.connectionErrors, "rejected_batches", c.stats.rejectedErrors,
+		"filtered", c.stats.filtered, "out_of_retention", c.stats.expired, "skipped", c.stats.skipped, "rejected_points", c.stats.rejectedPoints,
+		"failures", c.stats.failureSummary())
+	if cause != nil {
+		return cause
+	}
+	if code := c.stats.exitCode(); code != ExitCodeSuccess {
+		return &ExitError{Code: code, Err: fmt.Errorf("import finished with %d errors", c.stats.errors())}
+	}
+	return nil
+}
+
+// write the batch of n lines by fn which writes the lines in [lo, hi). The batch too large for
+// openGemini or partially written is split into halves, so that only the failed lines are
+// written again. The failure is retried with backoff by --on-error=retry.
+func (c *ImportCommand) write(ctx context.Context, n int, fn func(ctx context.Context, lo, hi int) error) error {
+	return c.writeRange(ctx, 0, n, fn)
+}
+
+func (c *ImportCommand) writeRange(ctx context.Context, lo, hi int, fn func(ctx context.Context, lo, hi int) error) error {
+	var backoff = importRetryBackoff
+	for retry := 0; ; retry++ {
+		start := time.Now()
+		c.throttled = 0
+		err := fn(ctx, lo, hi)
+		if c.adaptive != nil {
+			c.adaptive.observe(hi-lo, time.Since(start)-c.throttled, err)
+		}
+		if err == nil {
+			c.stats.written += hi - lo
+			return nil
+		}
+		writeErr := &importWriteError{lines: hi - lo, err: err}
+		if writeErr.tooLarge() && hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			slog.Warn("batch is too large, split it", "lines", hi-lo, "reason", err)
+			return errors.Join(c.writeRange(ctx, lo, mid, fn), c.writeRange(ctx, mid, hi, fn))
+		}
+		if errors.Is(err, errPartialWrite) && hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			slog.Warn("batch is partially written, isolate the failed lines", "lines", hi-lo, "reason", err)
+			return errors.Join(c.writeRange(ctx, lo, mid, fn), c.writeRange(ctx, mid, hi, fn))
+		}
+		if c.cfg.OnError != importOnErrorRetry || retry >= importMaxRetries || !writeErr.retryable() {
+			return writeErr
+		}
+		slog.Warn("write failed, retry later", "reason", err, "retry", retry+1, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return writeErr
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// executeDDL execute the statement, the error of the result is returned as well
+func (c *ImportCommand) executeDDL(ctx context.Context, command string) error {
+	if c.preflight != nil {
+		return nil
+	}
+	result, err := c.httpClient.Query(ctx, &opengemini.Query{Command: command})
+	if err == nil && result != nil {
+		if result.Error != "" {
+			err = errors.New(result.Error)
+		}
+		for _, res := range result.Results {
+			if res.Error != "" {
+				err = errors.New(res.Error)
+			}
+		}
+	}
+	if err != nil {
+		slog.Error("execute ddl failed", "reason", err, "command", command)
+		return &importDDLError{command: command, err: err}
+	}
+	slog.Info("execute ddl success", "command", command)
+	return nil
+}
+
+func validateOnError(onError string) error {
+	switch onError {
+	case "", importOnErrorAbort, importOnErrorSkip, importOnErrorRetry:
+		return nil
+	}
+	return fmt.Errorf("invalid --on-error %s, only support abort, skip, retry", onError)
+}
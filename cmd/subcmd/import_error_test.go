@@ -0,0 +1,101 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const importErrorContent = `a.b 1 1
+a.c x 1
+a.d 2 1
+a.e y 1
+a.f z 1
+`
+
+func TestImportOnError(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatGraphite, importErrorContent)
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 3, command.stats.parseErrors)
+	require.Equal(t, 2, command.stats.written)
+	require.Len(t, client.writes, 3)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, importErrorContent)
+	command.cfg.OnError = importOnErrorAbort
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 1, command.stats.parseErrors)
+	require.Equal(t, []string{"a.b value=1 1000000000", ""}, client.writes)
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, importErrorContent)
+	command.cfg.MaxErrors = 2
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 2, command.stats.parseErrors)
+	require.Equal(t, 2, command.stats.written)
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, importErrorContent)
+	command.cfg.OnError = "ignore"
+	requireExitCode(t, ExitCodeFailure, command.process())
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	require.NoError(t, command.process())
+}
+
+func TestImportDDLError(t *testing.T) {
+	content := `# DDL
+CREATE DATABASE db0
+# DML
+# CONTEXT-DATABASE: db0
+cpu v=1 1
+`
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	client.responder = func(command string) *opengemini.QueryResult {
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Error: "permission denied"}}}
+	}
+	requireExitCode(t, ExitCodeDDLError, command.process())
+	require.Equal(t, 1, command.stats.ddlErrors)
+	require.Equal(t, 1, command.stats.written)
+}
+
+func TestImportWriteError(t *testing.T) {
+	backoff := importRetryBackoff
+	importRetryBackoff = time.Millisecond
+	defer func() { importRetryBackoff = backoff }()
+
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	client.writeErr = errors.New("connection refused")
+	requireExitCode(t, ExitCodeConnection, command.process())
+	require.Equal(t, 1, client.writeCall)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.OnError = importOnErrorRetry
+	client.writeErr = errors.New("connection refused")
+	requireExitCode(t, ExitCodeConnection, command.process())
+	require.Equal(t, 1+importMaxRetries, client.writeCall)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.OnError = importOnErrorRetry
+	client.writeErr = &core.WriteError{StatusCode: http.StatusBadRequest}
+	requireExitCode(t, ExitCodePartial, command.process())
+	require.Equal(t, 1, client.writeCall)
+	require.Equal(t, 1, command.stats.rejectedErrors)
+}
@@ -15,9 +15,18 @@
 package subcmd
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/openGemini/opengemini-client-go/proto"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
 	"github.com/openGemini/openGemini-cli/core"
 )
@@ -51,29 +60,44 @@ func TestParseTimestamp(t *testing.T) {
 	}
 }
 
-func TestParse2String(t *testing.T) {
+func TestParseInfluxValue(t *testing.T) {
+	type testCase struct {
+		input   any
+		isFloat bool
+		expect  any
+	}
+
+	testCases := []testCase{
+		{json.Number("55"), false, int64(55)},
+		{json.Number("66.6"), true, 66.6},
+		{json.Number("66"), true, float64(66)},
+		{true, false, true},
+		{false, false, false},
+		{"royal", false, "royal"},
+	}
+
+	for _, tcase := range testCases {
+		act, err := parseInfluxValue(tcase.input, tcase.isFloat)
+		require.NoError(t, err)
+		require.Equal(t, tcase.expect, act)
+	}
+	_, err := parseInfluxValue(nil, false)
+	require.Error(t, err)
+}
+
+func TestParseInfluxTime(t *testing.T) {
 	type testCase struct {
-		fieldType int
 		precision string
-		inputAny  any
-		expect    string
+		input     any
+		expect    int64
 	}
 
 	testCases := []testCase{
-		{TypeField, "", 55, "55"},
-		{TypeField, "", 66.6, "66.6"},
-		{TypeField, "", true, "true"},
-		{TypeField, "", false, "false"},
-		{TypeField, "", "royal", "\"royal\""},
-		{TypeField, "", nil, "\"\""},
-
-		{TypeTimestamp, "", 1234567890, "1234567890"},
-		{TypeTimestamp, "", 1234567890.1, "1234567890.1"},
-		{TypeTimestamp, "s", "2010-07-01T18:48:00Z", "1278010080"},
-		{TypeTimestamp, "ns", "2010-07-01T18:48:00Z", "1278010080000000000"},
-		{TypeTimestamp, "ms", "2010-07-01T18:48:00Z", "1278010080000"},
-		{TypeTimestamp, "us", "2010-07-01T18:48:00Z", "1278010080000000"},
-		{TypeTimestamp, "", "2010-07-01T18:48:00ZZZ", ""},
+		{"", json.Number("1234567890"), 1234567890},
+		{"s", json.Number("1234567890"), 1234567890000000000},
+		{"s", json.Number("1234567890.5"), 1234567890500000000},
+		{"s", "2010-07-01T18:48:00Z", 1278010080000000000},
+		{"ms", "2010-07-01T18:48:00.123Z", 1278010080123000000},
 	}
 
 	for _, tcase := range testCases {
@@ -84,8 +108,106 @@ func TestParse2String(t *testing.T) {
 			c.cfg = cfg
 			err := c.cfg.configTimeMultiplier()
 			require.NoError(t, err)
-			act := c.parse2String(tcase.inputAny, tcase.fieldType)
+			act, err := c.parseInfluxTime(tcase.input)
+			require.NoError(t, err)
 			require.Equal(t, tcase.expect, act)
 		})
 	}
+	c := &ImportCommand{cfg: &ImportConfig{CommandLineConfig: new(core.CommandLineConfig)}}
+	_, err := c.parseInfluxTime("2010-07-01T18:48:00ZZZ")
+	require.Error(t, err)
+}
+
+type mockHttpClient struct {
+	queries    []string
+	writes     []string
+	responder  func(command string) *opengemini.QueryResult
+	writeErr   error
+	failWrites int // only the first writes fail by writeErr if it is not zero
+	writeCall  int
+	maxLines   int      // the write of more lines is rejected as too large
+	targets    []string // the database and retention policy of the writes
+
+	promWrites []*core.PromTimeSeries
+}
+
+func (m *mockHttpClient) SetDebug(debug bool) {}
+
+func (m *mockHttpClient) SetAuth(username, password string) {}
+
+func (m *mockHttpClient) SetToken(source core.TokenSource) {}
+
+func (m *mockHttpClient) SetCompression(compression string) error { return nil }
+
+func (m *mockHttpClient) Ping() error { return nil }
+
+func (m *mockHttpClient) Query(ctx context.Context, query *opengemini.Query) (*opengemini.QueryResult, error) {
+	m.queries = append(m.queries, query.Command)
+	if m.responder != nil {
+		return m.responder(query.Command), nil
+	}
+	return &opengemini.QueryResult{}, nil
+}
+
+func (m *mockHttpClient) Write(ctx context.Context, database, retentionPolicy, raw, precision string) error {
+	m.writeCall++
+	if m.writeErr != nil && (m.failWrites == 0 || m.writeCall <= m.failWrites) {
+		return m.writeErr
+	}
+	if m.maxLines > 0 && strings.Count(strings.TrimSpace(raw), "\n")+1 > m.maxLines {
+		return &core.WriteError{StatusCode: http.StatusRequestEntityTooLarge, Status: "413 Request Entity Too Large"}
+	}
+	m.targets = append(m.targets, database+"."+retentionPolicy)
+	m.writes = append(m.writes, strings.Split(raw, "\n")...)
+	return nil
+}
+
+func (m *mockHttpClient) PromWrite(ctx context.Context, database, retentionPolicy string, body []byte) error {
+	m.writeCall++
+	if m.writeErr != nil && (m.failWrites == 0 || m.writeCall <= m.failWrites) {
+		return m.writeErr
+	}
+	series, err := core.DecodePromRemoteWrite(body)
+	if err != nil {
+		return err
+	}
+	m.promWrites = append(m.promWrites, series...)
+	return nil
+}
+
+type mockWriteClient struct {
+	requests  []*proto.WriteRequest
+	responder func(in *proto.WriteRequest) proto.ResponseCode
+}
+
+func (m *mockWriteClient) Write(ctx context.Context, in *proto.WriteRequest, opts ...grpc.CallOption) (*proto.WriteResponse, error) {
+	if m.responder != nil {
+		if code := m.responder(in); code != proto.ResponseCode_Success {
+			return &proto.WriteResponse{Code: code}, nil
+		}
+	}
+	m.requests = append(m.requests, in)
+	return &proto.WriteResponse{Code: proto.ResponseCode_Success}, nil
+}
+
+func (m *mockWriteClient) Ping(ctx context.Context, in *proto.PingRequest, opts ...grpc.CallOption) (*proto.PingResponse, error) {
+	return &proto.PingResponse{}, nil
+}
+
+// newMockImportCommand create import command writes to mock http client
+func newMockImportCommand(t testing.TB, format, content string) (*ImportCommand, *mockHttpClient) {
+	path := filepath.Join(t.TempDir(), "import."+format)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	cfg := &ImportConfig{CommandLineConfig: new(core.CommandLineConfig), Path: path, Format: format, BatchSize: 100, TimeField: "time"}
+	cfg.Database = "db0"
+	cfg.RetentionPolicy = "autogen"
+	require.NoError(t, cfg.configTimeMultiplier())
+	client := new(mockHttpClient)
+	return &ImportCommand{cfg: cfg, httpClient: client, writeClient: new(mockWriteClient), fsm: new(ImportFileFSM)}, client
+}
+
+func requireExitCode(t *testing.T, code int, err error) {
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, code, exitErr.Code)
 }
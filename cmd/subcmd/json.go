@@ -15,17 +15,28 @@
 package subcmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/openGemini/opengemini-client-go/opengemini"
 
 	"github.com/openGemini/openGemini-cli/common"
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+// Prometheus HTTP API query result type, see https://prometheus.io/docs/prometheus/latest/querying/api/
+const (
+	promResultMatrix = "matrix"
+	promResultVector = "vector"
+	promResultScalar = "scalar"
+	promResultString = "string"
 )
 
 // JsonPResult prom json format
@@ -35,97 +46,276 @@ type JsonPResult struct {
 	Value  [2]any            `json:"value,omitempty"`
 }
 
-func (fsm *ImportFileFSM) processJsonP(dec *json.Decoder) (FSMCall, error) {
-	var res JsonPResult
+// PromQueryDecoder is a streaming decoder of the prometheus query response
+// `{"status":"success","data":{"resultType":"matrix","result":[...]}}`, the series in result
+// are decoded one by one, so that the large response is not loaded into memory.
+// Multiple responses in one file (such as NDJSON) are supported.
+type PromQueryDecoder struct {
+	dec *json.Decoder
+}
+
+func NewPromQueryDecoder(r io.Reader) *PromQueryDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &PromQueryDecoder{dec: dec}
+}
+
+// PromSeriesHandler handle one series of the result, the scalar and string result
+// is wrapped as a series without metric.
+type PromSeriesHandler func(resultType string, series *JsonPResult) error
+
+// Decode decodes all responses, the error returned by handler doesn't stop decoding,
+// all of them are joined and returned.
+func (d *PromQueryDecoder) Decode(handler PromSeriesHandler) error {
+	var errs error
+	for {
+		t, err := d.dec.Token()
+		if err == io.EOF {
+			return errs
+		}
+		if err != nil {
+			return errors.Join(errs, err)
+		}
+		if t != json.Delim('{') {
+			return errors.Join(errs, fmt.Errorf("invalid prom query response, expect '{' but got %v", t))
+		}
+		if err = d.decodeResponse(handler, &errs); err != nil {
+			return errors.Join(errs, err)
+		}
+	}
+}
+
+// decodeResponse decode the object after '{' of the response envelope
+func (d *PromQueryDecoder) decodeResponse(handler PromSeriesHandler, errs *error) error {
+	var status, errorMsg string
+	for d.dec.More() {
+		key, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "status":
+			if err = d.dec.Decode(&status); err != nil {
+				return err
+			}
+		case "error":
+			if err = d.dec.Decode(&errorMsg); err != nil {
+				return err
+			}
+		case "data":
+			if err = expectJSONDelim(d.dec, '{'); err != nil {
+				return err
+			}
+			if err = d.decodeData(handler, errs); err != nil {
+				return err
+			}
+		default: // errorType, warnings, infos
+			if err = skipJSONValue(d.dec); err != nil {
+				return err
+			}
+		}
+	}
+	if err := expectJSONDelim(d.dec, '}'); err != nil {
+		return err
+	}
+	if status != "" && status != "success" {
+		return fmt.Errorf("prom query response status %s: %s", status, errorMsg)
+	}
+	return nil
+}
+
+// decodeData decode the object after '{' of the data, the resultType is usually in front of
+// the result, otherwise it is inferred from the shape of the result.
+func (d *PromQueryDecoder) decodeData(handler PromSeriesHandler, errs *error) error {
+	var resultType string
+	for d.dec.More() {
+		key, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "resultType":
+			if err = d.dec.Decode(&resultType); err != nil {
+				return err
+			}
+		case "result":
+			if err = expectJSONDelim(d.dec, '['); err != nil {
+				return err
+			}
+			if err = d.decodeResult(resultType, handler, errs); err != nil {
+				return err
+			}
+		default:
+			if err = skipJSONValue(d.dec); err != nil {
+				return err
+			}
+		}
+	}
+	return expectJSONDelim(d.dec, '}')
+}
+
+// decodeResult decode the array after '[' of the result
+func (d *PromQueryDecoder) decodeResult(resultType string, handler PromSeriesHandler, errs *error) error {
+	if resultType == promResultScalar || resultType == promResultString {
+		var sample [2]any
+		for idx := 0; idx < 2 && d.dec.More(); idx++ {
+			if err := d.dec.Decode(&sample[idx]); err != nil {
+				return err
+			}
+		}
+		if err := handler(resultType, &JsonPResult{Value: sample}); err != nil {
+			*errs = errors.Join(*errs, err)
+		}
+		return expectJSONDelim(d.dec, ']')
+	}
+	for d.dec.More() {
+		var raw json.RawMessage
+		if err := d.dec.Decode(&raw); err != nil {
+			return err
+		}
+		var series JsonPResult
+		if len(raw) == 0 || raw[0] != '{' { // scalar without resultType: [ts, "value"]
+			series.Value[0] = json.Number(raw)
+			if resultType == "" {
+				resultType = promResultScalar
+			}
+			if d.dec.More() {
+				if err := d.dec.Decode(&series.Value[1]); err != nil {
+					return err
+				}
+			}
+			if err := handler(resultType, &series); err != nil {
+				*errs = errors.Join(*errs, err)
+			}
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&series); err != nil {
+			*errs = errors.Join(*errs, fmt.Errorf("decode series failed: %w", err))
+			continue
+		}
+		typ := resultType
+		if typ == "" {
+			typ = promResultVector
+			if series.Values != nil {
+				typ = promResultMatrix
+			}
+		}
+		if err := handler(typ, &series); err != nil {
+			*errs = errors.Join(*errs, err)
+		}
+	}
+	return expectJSONDelim(d.dec, ']')
+}
+
+// parsePromSampleTime parse the unix timestamp in seconds with fraction to nanosecond
+func parsePromSampleTime(v any) (int64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return parsePromTimestamp(v.String())
+	case float64:
+		return parsePromTimestamp(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return parsePromTimestamp(v)
+	}
+	return 0, fmt.Errorf("invalid sample timestamp %v", v)
+}
+
+// parsePromSampleValue parse the sample value, it is a string in the prometheus response
+func parsePromSampleValue(v any) (float64, error) {
+	switch v := v.(type) {
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("invalid sample value %v", v)
+}
+
+func (fsm *ImportFileFSM) processJsonP(resultType string, res *JsonPResult) (FSMCall, error) {
 	switch fsm.state {
 	case importStateDDL:
+		fsm.state = importStateDML
+		next, nextErr := fsm.processJsonP(resultType, res)
 		return func(ctx context.Context, command *ImportCommand) error {
-			t, err := dec.Token()
-			if err != nil {
-				slog.Error("parse prom json failed", "reason", err)
+			if err := command.createDatabase(ctx); err != nil {
 				return err
 			}
-			if t == "result" {
-				_, err := dec.Token() // skip [
-				if err != nil {
-					slog.Error("parse prom json failed", "reason", err)
-					return err
-				}
-				fsm.state = importStateDML
-				command.cfg.ColumnWrite = false // only support row protocol
-				// setup fsm config
-				cmdStr := fmt.Sprintf("CREATE DATABASE %s", command.cfg.Database)
-				_, err = command.httpClient.Query(ctx, &opengemini.Query{
-					Command: cmdStr, // CREATE DATABASE xxx
-				})
-				if err != nil {
-					slog.Error("execute ddl failed", "reason", err, "command", cmdStr)
-					return err
-				}
-				slog.Info("execute ddl success", "command", cmdStr)
-				fsm.database = command.cfg.Database
-				fsm.retentionPolicy = command.cfg.RetentionPolicy
-				fsm.measurement = command.cfg.Measurement
-				fsm.tagMap = make(map[string]FieldPos)
-				fsm.fieldMap = make(map[string]FieldPos)
-				if len(command.cfg.Fields) == 0 {
-					command.cfg.Fields = []string{"value"}
-				}
-				for _, tag := range command.cfg.Tags { // tags
-					fsm.tagMap[tag] = FieldPos{}
-				}
-				for _, field := range command.cfg.Fields { // fields
-					fsm.fieldMap[field] = FieldPos{}
-					break // field only one column
-				}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			if nextErr != nil {
+				return nextErr
 			}
-			return nil
+			return next(ctx, command)
 		}, nil
 	case importStateDML:
+		var samples = res.Values
+		if resultType != promResultMatrix {
+			samples = [][2]any{res.Value}
+		}
 		return func(ctx context.Context, command *ImportCommand) error {
-			if err := dec.Decode(&res); err != nil {
-				slog.Error("parse prom json failed", "reason", err)
-				return err
-			}
 			if command.fsm.database == "" {
 				return errors.New("database is required")
 			}
 			if command.fsm.retentionPolicy == "" {
 				command.fsm.retentionPolicy = common.DefaultRetentionPolicy // "autogen"
 			}
-			if command.cfg.Measurement == "" {
-				return errors.New("measurement is required")
+			var fieldName = promFieldValue
+			if len(command.cfg.Fields) != 0 {
+				fieldName = command.cfg.Fields[0]
 			}
-
-			// parse to datas "m,location=coyote_creek,randtag=2 index=15 1566123456"
-			var dataSlice []string
-			// if len(fsm.tagMap) > 0
-			line := command.fsm.measurement
-			if len(command.fsm.tagMap) > 0 {
-				for tag := range command.fsm.tagMap {
-					line += fmt.Sprintf(",%s=%s", tag, res.Metric[tag])
-				}
-			} else {
-				for tag, v := range res.Metric {
-					line += fmt.Sprintf(",%s=%s", tag, v)
-				}
+			var tags = make(map[string]string)
+			for key, value := range command.fsm.staticTags {
+				tags[key] = value
 			}
-
-			if len(res.Values) > 0 {
-				for _, v := range res.Values {
-					// v[0] is float64, parse to string
-					dataSlice = append(dataSlice, fmt.Sprintf("%s %s=%s %s", line, command.cfg.Fields[0], v[1], strconv.FormatFloat(v[0].(float64), 'f', -1, 64)))
+			for key, value := range res.Metric {
+				if _, ok := matchColumn(command.cfg.Tags, key); ok || len(command.cfg.Tags) == 0 {
+					tags[key] = value
 				}
-			} else {
-				dataSlice = append(dataSlice, fmt.Sprintf("%s %s=%s %s", line, command.cfg.Fields[0], res.Value[1], strconv.FormatFloat(res.Value[0].(float64), 'f', -1, 64)))
 			}
-
-			command.fsm.batchLPBuffer = append(command.fsm.batchLPBuffer, dataSlice...)
-			if len(command.fsm.batchLPBuffer) < command.cfg.BatchSize {
-				return nil
+			measurement := command.fsm.measurement
+			if measurement == "" { // use the metric name as measurement
+				measurement = res.Metric[promLabelName]
+				delete(tags, promLabelName)
+			}
+			if measurement == "" {
+				return fmt.Errorf("measurement is required, series %v has no __name__", res.Metric)
 			}
-			return command.excuteByLPBuffer(ctx)
 
+			var errs error
+			for _, sample := range samples {
+				timestamp, err := parsePromSampleTime(sample[0])
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("series %v: %w", res.Metric, err))
+					continue
+				}
+				var value any
+				if resultType == promResultString {
+					value = fmt.Sprint(sample[1])
+				} else {
+					fv, err := parsePromSampleValue(sample[1])
+					if err != nil {
+						errs = errors.Join(errs, fmt.Errorf("series %v: %w", res.Metric, err))
+						continue
+					}
+					if command.skipValue(fv, "series", res.Metric, "value", sample[1]) {
+						continue
+					}
+					value = fv
+				}
+				point := &opengemini.Point{
+					Measurement: measurement,
+					Timestamp:   timestamp,
+					Tags:        tags,
+					Fields:      map[string]interface{}{fieldName: value},
+				}
+				errs = errors.Join(errs, command.appendPoint(ctx, point))
+			}
+			return errs
 		}, nil
 	}
 
@@ -140,166 +330,279 @@ type JsonIResult struct {
 	Values      [][]any           `json:"values"`
 }
 
-func (fsm *ImportFileFSM) processJsonI(dec *json.Decoder) (FSMCall, error) {
-	var res JsonIResult
-	switch fsm.state {
-	case importStateDDL:
-		return func(ctx context.Context, command *ImportCommand) error {
-			t, err := dec.Token()
-			if err != nil {
-				slog.Error("parse influx json failed", "reason", err)
+// InfluxQueryDecoder is a streaming decoder of the influx query response
+// `{"results":[{"statement_id":0,"series":[...]}]}`, the series are decoded one by one.
+// Multiple responses in one file, such as chunked output, are supported.
+type InfluxQueryDecoder struct {
+	dec *json.Decoder
+}
+
+func NewInfluxQueryDecoder(r io.Reader) *InfluxQueryDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &InfluxQueryDecoder{dec: dec}
+}
+
+// Decode decodes all responses, the error of statement is joined and returned after all responses are decoded.
+func (d *InfluxQueryDecoder) Decode(handler func(series *JsonIResult) error) error {
+	var errs error
+	for {
+		t, err := d.dec.Token()
+		if err == io.EOF {
+			return errs
+		}
+		if err != nil {
+			return errors.Join(errs, err)
+		}
+		if t != json.Delim('{') {
+			return errors.Join(errs, fmt.Errorf("invalid influx query response, expect '{' but got %v", t))
+		}
+		if err = d.decodeObject(handler, &errs); err != nil {
+			return errors.Join(errs, err)
+		}
+	}
+}
+
+// decodeObject decode the object after '{', it is the response envelope or one of the results
+func (d *InfluxQueryDecoder) decodeObject(handler func(series *JsonIResult) error, errs *error) error {
+	for d.dec.More() {
+		key, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "results":
+			if err = expectJSONDelim(d.dec, '['); err != nil {
 				return err
 			}
-
-			if t == "series" {
-				_, err := dec.Token() // skip [
-				if err != nil {
-					slog.Error("parse influx json failed", "reason", err)
+			for d.dec.More() {
+				if err = expectJSONDelim(d.dec, '{'); err != nil {
 					return err
 				}
-
-				fsm.state = importStateDML
-				command.cfg.ColumnWrite = false // only support row protocol
-				// update db, rp
-				fsm.database = command.cfg.Database
-				fsm.retentionPolicy = command.cfg.RetentionPolicy
-
-				// create db
-				cmdStr := fmt.Sprintf("CREATE DATABASE %s", command.cfg.Database)
-				if _, err = command.httpClient.Query(ctx, &opengemini.Query{
-					Command: cmdStr, // CREATE DATABASE xxx
-				}); err != nil {
-					slog.Error("execute ddl failed", "reason", err, "command", cmdStr)
+				if err = d.decodeObject(handler, errs); err != nil {
 					return err
 				}
-				slog.Info("execute ddl success", "command", cmdStr)
 			}
-			return nil
-		}, nil
-	case importStateDML:
+			if err = expectJSONDelim(d.dec, ']'); err != nil {
+				return err
+			}
+		case "series":
+			if err = expectJSONDelim(d.dec, '['); err != nil {
+				return err
+			}
+			for d.dec.More() {
+				var series JsonIResult
+				if err = d.dec.Decode(&series); err != nil {
+					return err
+				}
+				if err = handler(&series); err != nil {
+					*errs = errors.Join(*errs, err)
+				}
+			}
+			if err = expectJSONDelim(d.dec, ']'); err != nil {
+				return err
+			}
+		case "error":
+			var message string
+			if err = d.dec.Decode(&message); err != nil {
+				return err
+			}
+			*errs = errors.Join(*errs, errors.New(message))
+		default: // statement_id, partial, messages
+			if err = skipJSONValue(d.dec); err != nil {
+				return err
+			}
+		}
+	}
+	return expectJSONDelim(d.dec, '}')
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("invalid json, expect '%s' but got %v", delim, t)
+	}
+	return nil
+}
+
+func skipJSONValue(dec *json.Decoder) error {
+	var skip json.RawMessage
+	return dec.Decode(&skip)
+}
+
+// influxFloatFields scan the whole input to infer the number fields of each measurement, the field is integer
+// only if all its values are integers, so that the type is stable across the series and chunks, and a float field
+// with integral values doesn't become integer. The errors of input are reported by the import.
+func influxFloatFields(reader io.Reader, measurement string) map[string]map[string]bool {
+	var floats = make(map[string]map[string]bool)
+	_ = NewInfluxQueryDecoder(reader).Decode(func(series *JsonIResult) error {
+		name := series.Measurement
+		if measurement != "" {
+			name = measurement
+		}
+		fields, ok := floats[name]
+		if !ok {
+			fields = make(map[string]bool)
+			floats[name] = fields
+		}
+		for _, value := range series.Values {
+			for idx, v := range value {
+				if idx >= len(series.Fields) {
+					break
+				}
+				if n, ok := v.(json.Number); ok && strings.ContainsAny(n.String(), ".eE") {
+					fields[series.Fields[idx]] = true
+				}
+			}
+		}
+		return nil
+	})
+	return floats
+}
+
+// parseInfluxValue convert json value to field value, number is integer or float by column type
+func parseInfluxValue(v any, isFloat bool) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if isFloat {
+			return v.Float64()
+		}
+		return v.Int64()
+	case float64:
+		return v, nil
+	case string, bool:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", v)
+}
+
+// parseInfluxTime parse time column, support epoch in --precision and RFC3339 string
+func (c *ImportCommand) parseInfluxTime(v any) (int64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if tsp, err := v.Int64(); err == nil {
+			return tsp * c.cfg.TimeMultiplier, nil
+		}
+		tsp, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return int64(tsp * float64(c.cfg.TimeMultiplier)), nil
+	case float64:
+		return int64(v * float64(c.cfg.TimeMultiplier)), nil
+	case string:
+		tt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, err
+		}
+		return tt.UnixNano(), nil
+	}
+	return 0, fmt.Errorf("invalid time %v", v)
+}
+
+func (fsm *ImportFileFSM) processJsonI(res *JsonIResult) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		next, nextErr := fsm.processJsonI(res)
 		return func(ctx context.Context, command *ImportCommand) error {
-			if err := dec.Decode(&res); err != nil {
-				slog.Error("parse influx json failed", "reason", err)
+			if err := command.createDatabase(ctx); err != nil {
 				return err
 			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			if nextErr != nil {
+				return nextErr
+			}
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		return func(ctx context.Context, command *ImportCommand) error {
 			if command.fsm.database == "" {
 				return errors.New("database is required")
 			}
 			if command.fsm.retentionPolicy == "" {
 				command.fsm.retentionPolicy = common.DefaultRetentionPolicy // "autogen"
 			}
+			measurement := res.Measurement
+			if command.fsm.measurement != "" {
+				measurement = command.fsm.measurement
+			}
+			if measurement == "" {
+				return errors.New("measurement is required")
+			}
 
-			// parse to datas "m,location=coyote_creek,randtag=2 index=15 1566123456"
-			var dataSlice []string
-
-			// parse struct
-			line := res.Measurement
-			if len(res.Tags) != 0 {
-				for tag, val := range res.Tags {
-					line += fmt.Sprintf(",%s=%s", tag, val)
+			var timePos = -1
+			var tagsPos, fieldsPos []int
+			for idx, column := range res.Fields {
+				if column == common.ColumnNameTime || column == command.cfg.TimeField {
+					timePos = idx
+					continue
 				}
-			}
-			// reuse fsm config
-			command.fsm.clearFieldConfig()
-			for i, field := range res.Fields {
-				if field == "time" {
-					command.fsm.timeField = FieldPos{field, i}
-				} else {
-					command.fsm.fieldMap[field] = FieldPos{field, i}
+				if _, ok := matchColumn(command.cfg.Drops, column); ok {
+					continue
+				}
+				if _, ok := matchColumn(command.cfg.Tags, column); ok {
+					tagsPos = append(tagsPos, idx)
+					continue
+				}
+				if _, ok := matchColumn(command.cfg.Fields, column); ok || len(command.cfg.Fields) == 0 {
+					fieldsPos = append(fieldsPos, idx)
 				}
 			}
+			isFloat := command.fsm.influxFloats[measurement]
 
-			// parse the value of fields
+			var errs error
 			for _, value := range res.Values {
-				var fields string
-				var timestamp string
-				var tidx int = -1 // time column not exist
-				if command.fsm.timeField.Name != "" {
-					tidx = command.fsm.timeField.Pos
+				var point = &opengemini.Point{
+					Measurement: measurement,
+					Timestamp:   core.NoTimestamp, // the row without time column
+					Tags:        make(map[string]string),
+					Fields:      make(map[string]interface{}),
 				}
-				for _, field := range command.fsm.fieldMap {
-					if field.Pos < len(value) {
-						fk, fv := field.Name, value[field.Pos] // fields value (string, float64, int64, bool)  ->  string
-						fields += fmt.Sprintf("%s=%s,", fk, command.parse2String(fv, TypeField))
+				for key, val := range command.fsm.staticTags {
+					point.Tags[key] = val
+				}
+				for key, val := range res.Tags {
+					point.Tags[key] = val
+				}
+				for _, idx := range tagsPos {
+					if idx < len(value) && value[idx] != nil {
+						point.Tags[res.Fields[idx]] = fmt.Sprint(value[idx])
 					}
 				}
-				if len(fields) > 0 {
-					fields = fields[:len(fields)-1]
+				for _, idx := range fieldsPos {
+					if idx >= len(value) || value[idx] == nil {
+						continue
+					}
+					fv, err := parseInfluxValue(value[idx], isFloat[res.Fields[idx]])
+					if err != nil {
+						errs = errors.Join(errs, fmt.Errorf("series %s column %s: %w", measurement, res.Fields[idx], err))
+						continue
+					}
+					point.Fields[res.Fields[idx]] = fv
 				}
-
-				if tidx != -1 && tidx < len(value) {
-					tsp := value[tidx] // 1234567890 or "2010-07-01T18:48:00Z" -> "1234567890"
-					timestamp = command.parse2String(tsp, TypeTimestamp)
+				if len(point.Fields) == 0 {
+					continue
 				}
-
-				if tidx == -1 || timestamp == "" {
-					dataSlice = append(dataSlice, fmt.Sprintf("%s %s", line, fields))
-				} else {
-					dataSlice = append(dataSlice, fmt.Sprintf("%s %s %s", line, fields, timestamp))
+				if timePos != -1 && timePos < len(value) {
+					tsp, err := command.parseInfluxTime(value[timePos])
+					if err != nil {
+						errs = errors.Join(errs, fmt.Errorf("series %s: %w", measurement, err))
+						continue
+					}
+					point.Timestamp = tsp
 				}
 
+				errs = errors.Join(errs, command.appendPoint(ctx, point))
 			}
-
-			command.fsm.batchLPBuffer = append(command.fsm.batchLPBuffer, dataSlice...)
-			if len(command.fsm.batchLPBuffer) < command.cfg.BatchSize {
-				return nil
-			}
-			return command.excuteByLPBuffer(ctx)
+			return errs
 		}, nil
 	}
 
 	return FSMCallEmpty, nil
 }
-
-func (fsm *ImportFileFSM) clearFieldConfig() {
-	fsm.tagMap = make(map[string]FieldPos)
-	fsm.fieldMap = make(map[string]FieldPos)
-	fsm.timeField = FieldPos{}
-}
-
-const (
-	TypeTimestamp = iota
-	TypeField
-)
-
-func (c *ImportCommand) parse2String(s any, t int) string {
-	if t == TypeField { // field
-		switch s := s.(type) { // fields value (string, float64, int64, bool)  ->  string
-		case float64:
-			return strconv.FormatFloat(s, 'f', -1, 64)
-		case int64:
-			return strconv.FormatInt(s, 10)
-		case int32:
-			return strconv.FormatInt(int64(s), 10)
-		case int:
-			return strconv.FormatInt(int64(s), 10)
-		case bool:
-			if s {
-				return "true"
-			}
-			return "false"
-		case string:
-			return fmt.Sprintf("\"%s\"", s)
-		}
-		return "\"\""
-	} else if t == TypeTimestamp { // 1234567890 or "2010-07-01T18:48:00Z" -> "1234567890"
-		switch s := s.(type) {
-		case float64:
-			return strconv.FormatFloat(s, 'f', -1, 64)
-		case int64:
-			return strconv.FormatInt(s, 10)
-		case int32:
-			return strconv.FormatInt(int64(s), 10)
-		case int:
-			return strconv.FormatInt(int64(s), 10)
-		case string: // must parse "2010-07-01T18:48:00Z" ->  "1234567890"
-			if tt, err := time.Parse(time.RFC3339, s); err != nil {
-				return ""
-			} else {
-				tsp := tt.UnixNano() / c.cfg.TimeMultiplier
-				return strconv.FormatFloat(float64(tsp), 'f', -1, 64)
-			}
-		}
-	}
-	return ""
-}
@@ -0,0 +1,102 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromQueryDecoder(t *testing.T) {
+	content := `{"status":"success","data":{"resultType":"matrix","result":[
+  {"metric":{"__name__":"up","job":"node"},"values":[[1435781430.781,"1"],[1435781445.781,"0"]]},
+  {"metric":{"__name__":"up","job":"prom"},"values":[[1435781430.781,1]]}
+]}}
+{"status":"success","data":{"result":[{"metric":{"job":"node"},"value":[1435781451.781,"NaN"]}]}}
+{"status":"success","data":{"resultType":"scalar","result":[1435781451.781,"1.5"]}}
+{"status":"error","errorType":"bad_data","error":"parse error"}`
+
+	var types []string
+	var series []*JsonPResult
+	err := NewPromQueryDecoder(strings.NewReader(content)).Decode(func(resultType string, res *JsonPResult) error {
+		types = append(types, resultType)
+		series = append(series, res)
+		return nil
+	})
+	require.ErrorContains(t, err, "parse error")
+	require.Equal(t, []string{promResultMatrix, promResultMatrix, promResultVector, promResultScalar}, types)
+	require.Equal(t, "node", series[0].Metric["job"])
+	require.Len(t, series[0].Values, 2)
+	require.Equal(t, "1.5", series[3].Value[1])
+}
+
+func TestProcessJsonP(t *testing.T) {
+	content := `{"status":"success","data":{"resultType":"matrix","result":[
+  {"metric":{"__name__":"up","job":"node","instance":"a:9100"},"values":[[1435781430.781,"1"],[1435781445.781,"+Inf"]]},
+  {"metric":{"job":"missing_name"},"values":[[1435781430.781,"1"]]},
+  {"metric":{"__name__":"up","job":"prom"},"values":[[1435781430,"x"],[1435781430,"2"]]}
+]}}`
+	command, client := newMockImportCommand(t, importFormatJSONProm, content)
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, 1, command.stats.skipped)
+	require.Equal(t, []string{
+		"up,instance=a:9100,job=node value=1 1435781430781000000",
+		"up,job=prom value=2 1435781430000000000",
+		"",
+	}, client.writes)
+}
+
+func TestProcessJsonPSingleMeasurement(t *testing.T) {
+	content := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"node"},"value":[1435781430,"1"]}]}}`
+	command, client := newMockImportCommand(t, importFormatJSONProm, content)
+	command.cfg.Measurement = "prometheus"
+	command.cfg.Tags = []string{"job"}
+	require.NoError(t, command.process())
+	require.Equal(t, "prometheus,job=node value=1 1435781430000000000", client.writes[0])
+}
+
+func TestProcessJsonI(t *testing.T) {
+	content := `{"results":[{"statement_id":0,"series":[
+  {"name":"cpu","tags":{"host":"h1"},"columns":["time","usage","count","note","ok"],"values":[
+    ["2020-01-01T00:00:01Z",1,3,"say \"hi\"",true],
+    ["2020-01-01T00:00:02Z",2.5,4,null,false]]},
+  {"name":"mem","columns":["time","used","region"],"values":[[1577836801000000000,10,"eu"]]}
+]},{"statement_id":1,"error":"measurement not found"}]}
+{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"host":"h2"},"columns":["time","usage"],"values":[["2020-01-01T00:00:01Z",7]]}],"partial":true}]}
+`
+	command, client := newMockImportCommand(t, importFormatJSONInflux, content)
+	command.cfg.Tags = []string{"region"}
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{
+		`cpu,host=h1 count=3i,note="say \"hi\"",ok=true,usage=1 1577836801000000000`,
+		`cpu,host=h1 count=4i,ok=false,usage=2.5 1577836802000000000`,
+		`mem,region=eu used=10i 1577836801000000000`,
+		// usage is float across the series as h1 has float values
+		`cpu,host=h2 usage=7 1577836801000000000`,
+		"",
+	}, client.writes)
+}
+
+func TestProcessJsonIWithoutTime(t *testing.T) {
+	// the row without time column is written without timestamp, the server assigns its time
+	content := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["usage"],"values":[[1]]}]}]}`
+	command, client := newMockImportCommand(t, importFormatJSONInflux, content)
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"cpu usage=1i", ""}, client.writes)
+}
@@ -0,0 +1,455 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/common"
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+// JSONMapping describe how to map a json document to points, the expression is JSONPath-like:
+// `$` is the document, `@` is the element of the expanded array, followed by `.key`, `["key"]`
+// or `[index]`, such as `$.device.id` and `@.values[0]`. The value not starting with `$` or `@`
+// is a literal.
+//
+//	{
+//	  "measurement": "$.type",
+//	  "tags": {"device": "$.device.id", "site": "plant1"},
+//	  "fields": {"temp": "@.temp", "env": "$.env"},
+//	  "time": "@.ts",
+//	  "expand": "$.readings"
+//	}
+//
+// The object field is flattened to dotted field names, `env` above produces `env.humidity`,
+// `env.pressure`, etc. If fields is empty, all the remaining values of the document (or the
+// expanded element) are flattened to fields. All numbers are written as float.
+type JSONMapping struct {
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags"`
+	Fields      map[string]string `json:"fields"`
+	Time        string            `json:"time"`
+	Expand      string            `json:"expand"`
+
+	timeOptional bool // the time is the default --time, the document may have no time
+}
+
+// newJSONMapping load the mapping from --mapping file, the missing parts are filled by
+// --measurement, --tags, --fields and --time.
+func newJSONMapping(cfg *ImportConfig) (*JSONMapping, error) {
+	var mapping = new(JSONMapping)
+	if cfg.Mapping != "" {
+		content, err := os.ReadFile(cfg.Mapping)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(content, mapping); err != nil {
+			return nil, fmt.Errorf("invalid mapping file %s: %w", cfg.Mapping, err)
+		}
+	}
+	var root = "$"
+	if mapping.Expand != "" {
+		root = "@"
+	}
+	var pathOf = func(name string) string {
+		if strings.HasPrefix(name, "$") || strings.HasPrefix(name, "@") {
+			return name
+		}
+		return root + "." + name
+	}
+	if mapping.Measurement == "" {
+		mapping.Measurement = cfg.Measurement
+	}
+	if len(mapping.Tags) == 0 && len(cfg.Tags) != 0 {
+		mapping.Tags = make(map[string]string)
+		for _, tag := range cfg.Tags {
+			mapping.Tags[strings.TrimLeft(tag, "$@.")] = pathOf(tag)
+		}
+	}
+	if len(mapping.Fields) == 0 && len(cfg.Fields) != 0 {
+		mapping.Fields = make(map[string]string)
+		for _, field := range cfg.Fields {
+			mapping.Fields[strings.TrimLeft(field, "$@.")] = pathOf(field)
+		}
+	}
+	if mapping.Time == "" && cfg.TimeField != "" {
+		mapping.Time = pathOf(cfg.TimeField)
+		mapping.timeOptional = cfg.TimeFieldOptional
+	}
+
+	// check expressions
+	var exprs = []string{mapping.Measurement, mapping.Time, mapping.Expand}
+	for _, expr := range mapping.Tags {
+		exprs = append(exprs, expr)
+	}
+	for _, expr := range mapping.Fields {
+		exprs = append(exprs, expr)
+	}
+	for _, expr := range exprs {
+		if !isJSONPath(expr) {
+			continue
+		}
+		if _, err := parseJSONPath(expr); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(expr, "@") && mapping.Expand == "" {
+			return nil, fmt.Errorf("invalid expression %s, '@' is only available with expand", expr)
+		}
+	}
+	return mapping, nil
+}
+
+func isJSONPath(expr string) bool {
+	return strings.HasPrefix(expr, "$") || strings.HasPrefix(expr, "@")
+}
+
+// parseJSONPath parse the expression to path segments, the segment is string key or int index
+func parseJSONPath(expr string) ([]any, error) {
+	var segments []any
+	var s = expr[1:]
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid expression %s, empty key", expr)
+			}
+			segments = append(segments, s[:end])
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid expression %s, missing ']'", expr)
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			if key, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, key)
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression %s, invalid index %s", expr, inner)
+			}
+			segments = append(segments, idx)
+		default:
+			return nil, fmt.Errorf("invalid expression %s", expr)
+		}
+	}
+	return segments, nil
+}
+
+// evalJSONPath returns the value of expression, false if not exist
+func evalJSONPath(expr string, doc, elem any) (any, bool) {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, false
+	}
+	var current = doc
+	if expr[0] == '@' {
+		current = elem
+	}
+	for _, segment := range segments {
+		switch key := segment.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[key]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := current.([]any)
+			if !ok || key < 0 || key >= len(array) {
+				return nil, false
+			}
+			current = array[key]
+		}
+	}
+	return current, current != nil
+}
+
+// flattenJSON flatten the value to fields, the nested key is joined by dot
+func flattenJSON(prefix string, value any, fields map[string]any) {
+	var join = func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			flattenJSON(join(key), val, fields)
+		}
+	case []any:
+		for idx, val := range v {
+			flattenJSON(join(strconv.Itoa(idx)), val, fields)
+		}
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			fields[prefix] = f
+		}
+	case string, bool:
+		fields[prefix] = v
+	}
+}
+
+func jsonScalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// parseJSONTime parse the time value, number is in --precision, string is RFC3339
+func (c *ImportCommand) parseJSONTime(value any) (int64, error) {
+	if s, ok := value.(string); ok && checkIsNumber(s) {
+		value = json.Number(s)
+	}
+	return c.parseInfluxTime(value)
+}
+
+// jsonDocumentPoints map one document to points
+func (c *ImportCommand) jsonDocumentPoints(mapping *JSONMapping, doc any) ([]*opengemini.Point, error) {
+	var elements = []any{doc}
+	if mapping.Expand != "" {
+		value, ok := evalJSONPath(mapping.Expand, doc, nil)
+		array, isArray := value.([]any)
+		if !ok || !isArray {
+			return nil, fmt.Errorf("expand %s is not an array", mapping.Expand)
+		}
+		elements = array
+	}
+
+	var points []*opengemini.Point
+	for idx, elem := range elements {
+		var eval = func(expr string) (any, bool) {
+			if !isJSONPath(expr) {
+				return expr, expr != ""
+			}
+			return evalJSONPath(expr, doc, elem)
+		}
+		var point = &opengemini.Point{Tags: make(map[string]string), Fields: make(map[string]interface{})}
+		measurement, ok := eval(mapping.Measurement)
+		if point.Measurement, _ = jsonScalarString(measurement); !ok || point.Measurement == "" {
+			return nil, fmt.Errorf("element %d: measurement %s not found", idx, mapping.Measurement)
+		}
+		for key, value := range c.fsm.staticTags {
+			point.Tags[key] = value
+		}
+		var used = map[string]bool{}
+		for _, name := range core.SortedKeys(mapping.Tags) {
+			expr := mapping.Tags[name]
+			used[strings.TrimLeft(expr, "$@.")] = true
+			value, ok := eval(expr)
+			if !ok {
+				continue
+			}
+			if point.Tags[name], ok = jsonScalarString(value); !ok {
+				return nil, fmt.Errorf("element %d: tag %s must be a scalar value", idx, name)
+			}
+		}
+		point.Timestamp = core.NoTimestamp
+		if mapping.Time != "" {
+			used[strings.TrimLeft(mapping.Time, "$@.")] = true
+			value, ok := eval(mapping.Time)
+			if !ok && !mapping.timeOptional {
+				return nil, fmt.Errorf("element %d: time %s not found", idx, mapping.Time)
+			}
+			if ok {
+				tsp, err := c.parseJSONTime(value)
+				if err != nil {
+					return nil, fmt.Errorf("element %d: parse time failed: %w", idx, err)
+				}
+				point.Timestamp = tsp
+			}
+		}
+		if len(mapping.Fields) != 0 {
+			for name, expr := range mapping.Fields {
+				if value, ok := eval(expr); ok {
+					flattenJSON(name, value, point.Fields)
+				}
+			}
+		} else { // the remaining values of the element are fields
+			var remaining = make(map[string]any)
+			flattenJSON("", elem, remaining)
+			for name, value := range remaining {
+				if !used[name] {
+					point.Fields[name] = value
+				}
+			}
+		}
+		if len(point.Fields) == 0 {
+			return nil, fmt.Errorf("element %d: no fields", idx)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// JSONDocumentReader reads documents from NDJSON file or json array, the documents of NDJSON
+// are read line by line, so that an invalid line doesn't stop the reading.
+type JSONDocumentReader struct {
+	r     *bufio.Reader
+	dec   *json.Decoder // json array
+	index int
+}
+
+func NewJSONDocumentReader(r io.Reader) (*JSONDocumentReader, error) {
+	reader := &JSONDocumentReader{r: bufio.NewReader(r)}
+	first, err := peekNonSpace(reader.r)
+	if err != nil {
+		if err == io.EOF {
+			return reader, nil
+		}
+		return nil, err
+	}
+	if first == '[' {
+		reader.dec = json.NewDecoder(reader.r)
+		reader.dec.UseNumber()
+		if err = expectJSONDelim(reader.dec, '['); err != nil {
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
+// peekNonSpace discard the leading spaces and returns the first non-space character
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\r' && b[0] != '\n' {
+			return b[0], nil
+		}
+		_, _ = r.ReadByte()
+	}
+}
+
+// Read returns the next document and its position, the line number of NDJSON or the index of
+// array element. A decode error of one NDJSON line is returned as *jsonDocumentError, the
+// reading can continue after it.
+func (r *JSONDocumentReader) Read() (any, int, error) {
+	if r.dec != nil {
+		if !r.dec.More() {
+			if err := expectJSONDelim(r.dec, ']'); err != nil {
+				return nil, r.index, err
+			}
+			return nil, r.index, io.EOF
+		}
+		r.index++
+		var doc any
+		if err := r.dec.Decode(&doc); err != nil {
+			return nil, r.index, err
+		}
+		return doc, r.index, nil
+	}
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, r.index, err
+		}
+		r.index++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var doc any
+		if err = dec.Decode(&doc); err != nil {
+			return nil, r.index, &jsonDocumentError{err: err}
+		}
+		return doc, r.index, nil
+	}
+}
+
+// jsonDocumentError the document is invalid, the reading can continue
+type jsonDocumentError struct {
+	err error
+}
+
+func (e *jsonDocumentError) Error() string {
+	return e.err.Error()
+}
+
+func (fsm *ImportFileFSM) processNDJSON(doc any) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		next, nextErr := fsm.processNDJSON(doc)
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			var err error
+			if fsm.jsonMapping, err = newJSONMapping(command.cfg); err != nil {
+				return err
+			}
+			if nextErr != nil {
+				return nextErr
+			}
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" || fsm.jsonMapping == nil {
+				return errors.New("database is required")
+			}
+			if fsm.retentionPolicy == "" {
+				fsm.retentionPolicy = common.DefaultRetentionPolicy // "autogen"
+			}
+			points, err := command.jsonDocumentPoints(fsm.jsonMapping, doc)
+			if err != nil {
+				return err
+			}
+			for _, point := range points {
+				if err = command.appendPoint(ctx, point); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
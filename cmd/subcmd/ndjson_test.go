@@ -0,0 +1,112 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(`$.device.values[1]["a.b"]`)
+	require.NoError(t, err)
+	require.Equal(t, []any{"device", "values", 1, "a.b"}, segments)
+
+	for _, expr := range []string{"$..a", "$.a[", "$.a[x]", "$a"} {
+		_, err = parseJSONPath(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestProcessNDJSON(t *testing.T) {
+	content := `{"time":1577836801,"host":"h1","cpu":{"user":1.5,"sys":2},"ok":true}
+
+{"time":1577836802,"host":"h2","cpu":{"user":3}
+{"time":"2020-01-01T00:00:03Z","host":"h3","cpu":{"user":4},"note":"x"}
+{"host":"h4","cpu":{"user":5}}
+`
+	command, client := newMockImportCommand(t, importFormatNDJSON, content)
+	command.cfg.Measurement = "cpu"
+	command.cfg.Tags = []string{"host"}
+	command.cfg.Precision = "s"
+	require.NoError(t, command.cfg.configTimeMultiplier())
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{
+		"cpu,host=h1 cpu.sys=2,cpu.user=1.5,ok=true 1577836801000000000",
+		"cpu,host=h3 cpu.user=4,note=\"x\" 1577836803000000000",
+		"",
+	}, client.writes)
+}
+
+func TestProcessNDJSONWithoutTime(t *testing.T) {
+	content := `{"time":1577836801,"host":"h1","value":1}
+{"host":"h2","value":2}
+`
+	command, client := newMockImportCommand(t, importFormatNDJSON, content)
+	command.cfg.Measurement = "cpu"
+	command.cfg.Tags = []string{"host"}
+	command.cfg.Precision = "s"
+	command.cfg.TimeFieldOptional = true // --time is not passed
+	require.NoError(t, command.cfg.configTimeMultiplier())
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		"cpu,host=h1 value=1 1577836801000000000",
+		"cpu,host=h2 value=2",
+		"",
+	}, client.writes)
+}
+
+func TestProcessNDJSONMapping(t *testing.T) {
+	content := `[
+  {"type":"env","device":{"id":"d1"},"readings":[{"ts":1,"temp":20.5,"extra":{"hum":40}},{"ts":2,"temp":21}]},
+  {"type":"env","device":{"id":"d2"},"readings":{"ts":3}}
+]`
+	mapping := `{"measurement":"$.type","tags":{"device":"$.device.id","site":"plant1"},
+"fields":{"temp":"@.temp","env":"@.extra"},"time":"@.ts","expand":"$.readings"}`
+	command, client := newMockImportCommand(t, importFormatNDJSON, content)
+	command.cfg.Mapping = filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(command.cfg.Mapping, []byte(mapping), 0600))
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, []string{
+		"env,device=d1,site=plant1 env.hum=40,temp=20.5 1",
+		"env,device=d1,site=plant1 temp=21 2",
+		"",
+	}, client.writes)
+}
+
+func TestProcessNDJSONInvalidMapping(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatNDJSON, `{"time":1,"value":1}`)
+	command.cfg.Mapping = filepath.Join(t.TempDir(), "mapping.json")
+	require.NoError(t, os.WriteFile(command.cfg.Mapping, []byte(`{"fields":`), 0600))
+	err := command.process()
+	requireExitCode(t, ExitCodeFailure, err)
+	require.ErrorContains(t, err, "invalid mapping file")
+	require.Empty(t, client.queries)
+}
+
+func TestNewJSONMappingError(t *testing.T) {
+	command, _ := newMockImportCommand(t, importFormatNDJSON, "")
+	command.cfg.Fields = []string{"@.value"}
+	_, err := newJSONMapping(command.cfg)
+	require.ErrorContains(t, err, "only available with expand")
+
+	command.cfg.Fields = []string{"$.a["}
+	_, err = newJSONMapping(command.cfg)
+	require.Error(t, err)
+}
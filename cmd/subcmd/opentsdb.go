@@ -0,0 +1,158 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// OpenTSDB telnet and http api format, see http://opentsdb.net/docs/build/html/api_telnet/put.html
+// and http://opentsdb.net/docs/build/html/api_http/put.html
+const (
+	openTSDBPut       = "put"
+	openTSDBTagMetric = "metric"
+)
+
+// OpenTSDBDataPoint is a data point of OpenTSDB
+type OpenTSDBDataPoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp json.Number       `json:"timestamp"`
+	Value     json.Number       `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// parseOpenTSDBTelnet parse line like `put sys.cpu.user 1356998400 42.5 host=webserver01 cpu=0`
+func parseOpenTSDBTelnet(line string) (*OpenTSDBDataPoint, error) {
+	parts := strings.Fields(line)
+	if len(parts) == 0 || parts[0] != openTSDBPut {
+		return nil, fmt.Errorf("unsupported command %q", line)
+	}
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("invalid put %q, expect 'put <metric> <timestamp> <value> <tagk=tagv>...'", line)
+	}
+	var dp = &OpenTSDBDataPoint{
+		Metric:    parts[1],
+		Timestamp: json.Number(parts[2]),
+		Value:     json.Number(parts[3]),
+		Tags:      make(map[string]string),
+	}
+	for _, tag := range parts[4:] {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		dp.Tags[key] = value
+	}
+	return dp, nil
+}
+
+// parseOpenTSDBJSON parse the body of /api/put, which is a single data point or an array
+func parseOpenTSDBJSON(doc any) ([]*OpenTSDBDataPoint, error) {
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := doc.([]any); !ok {
+		content = append(append([]byte{'['}, content...), ']')
+	}
+	var dps []*OpenTSDBDataPoint
+	if err = json.Unmarshal(content, &dps); err != nil {
+		return nil, fmt.Errorf("invalid opentsdb json: %w", err)
+	}
+	return dps, nil
+}
+
+// point convert the data point to point, the value is always float
+func (dp *OpenTSDBDataPoint) point(fieldName string) (*opengemini.Point, error) {
+	if dp.Metric == "" {
+		return nil, errors.New("missing metric name")
+	}
+	value, err := strconv.ParseFloat(dp.Value.String(), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of metric %s", dp.Value, dp.Metric)
+	}
+	// the timestamp is seconds or milliseconds
+	timestamp, err := parsePromTimestamp(dp.Timestamp.String())
+	if err != nil {
+		return nil, err
+	}
+	var point = &opengemini.Point{
+		Measurement: dp.Metric,
+		Timestamp:   timestamp,
+		Tags:        make(map[string]string, len(dp.Tags)),
+		Fields:      map[string]interface{}{fieldName: value},
+	}
+	for key, value := range dp.Tags {
+		point.Tags[key] = value
+	}
+	return point, nil
+}
+
+func (fsm *ImportFileFSM) processOpenTSDB(dps []*OpenTSDBDataPoint) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		next, _ := fsm.processOpenTSDB(dps) // never returns error in dml state
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" {
+				return errors.New("database is required")
+			}
+			var fieldName = promFieldValue
+			if len(command.cfg.Fields) != 0 {
+				fieldName = command.cfg.Fields[0]
+			}
+			var errs error
+			for _, dp := range dps {
+				point, err := dp.point(fieldName)
+				if err != nil {
+					errs = errors.Join(errs, err)
+					continue
+				}
+				if command.skipValue(point.Fields[fieldName].(float64), "metric", dp.Metric, "value", dp.Value) {
+					continue
+				}
+				if fsm.measurement != "" { // single measurement, the metric name as tag
+					point.Tags[openTSDBTagMetric] = point.Measurement
+					point.Measurement = fsm.measurement
+				}
+				for key, value := range fsm.staticTags {
+					point.Tags[key] = value
+				}
+				if err = command.appendPivotPoint(ctx, point); err != nil {
+					return err
+				}
+			}
+			return errs
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenTSDBTelnet(t *testing.T) {
+	dp, err := parseOpenTSDBTelnet("put sys.cpu.user 1356998400 42.5 host=web01 cpu=0")
+	require.NoError(t, err)
+	require.Equal(t, "sys.cpu.user", dp.Metric)
+	require.Equal(t, map[string]string{"host": "web01", "cpu": "0"}, dp.Tags)
+
+	for _, line := range []string{"version", "put sys.cpu.user 1356998400", "put sys.cpu.user 1356998400 1 host"} {
+		_, err = parseOpenTSDBTelnet(line)
+		require.Error(t, err, line)
+	}
+}
+
+func TestProcessOpenTSDBTelnet(t *testing.T) {
+	content := `put sys.cpu.user 1356998400 42.5 host=web01 cpu=0
+put sys.cpu.user 1356998400500 1 host=web02
+put sys.cpu.user abc 1 host=web02
+put sys.cpu.nice 1356998401 x host=web01
+put sys.cpu.nice 1356998401 NaN host=web01
+put sys.cpu.nice 1356998401 -Inf host=web02
+`
+	command, client := newMockImportCommand(t, importFormatOpenTSDB, content)
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Equal(t, 2, command.stats.skipped)
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{
+		"sys.cpu.user,cpu=0,host=web01 value=42.5 1356998400000000000",
+		"sys.cpu.user,host=web02 value=1 1356998400500000000",
+		"",
+	}, client.writes)
+}
+
+func TestProcessOpenTSDBJSON(t *testing.T) {
+	content := `[
+  {"metric":"sys.cpu.nice","timestamp":1346846400,"value":18,"tags":{"host":"web01"}},
+  {"metric":"sys.cpu.nice","timestamp":1346846400,"value":"9","tags":{"host":"web02"}}
+]`
+	command, client := newMockImportCommand(t, importFormatOpenTSDB, content)
+	command.cfg.Measurement = "opentsdb"
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		"opentsdb,host=web01,metric=sys.cpu.nice value=18 1346846400000000000",
+		"opentsdb,host=web02,metric=sys.cpu.nice value=9 1346846400000000000",
+		"",
+	}, client.writes)
+}
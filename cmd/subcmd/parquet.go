@@ -0,0 +1,313 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+const (
+	parquetReadRows = 1024
+	// julianDayOfUnixEpoch the julian day of 1970-01-01, used by the legacy INT96 timestamp
+	julianDayOfUnixEpoch = 2440588
+)
+
+// ParquetColumn is a top-level leaf column of parquet file
+type ParquetColumn struct {
+	Name  string
+	Index int // the column index of parquet value
+	Type  parquet.Type
+}
+
+// ParquetReader reads the rows of parquet file as typed values, the value is nil, bool, int64,
+// float64, string or time.Time. The nested columns are not supported and ignored.
+type ParquetReader struct {
+	reader  *parquet.Reader
+	columns []ParquetColumn
+	rows    []parquet.Row
+	pending []parquet.Row
+	line    int
+}
+
+func NewParquetReader(r io.ReaderAt, size int64) (*ParquetReader, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var reader = &ParquetReader{reader: parquet.NewReader(file), rows: make([]parquet.Row, parquetReadRows)}
+	var schema = file.Schema()
+	for _, field := range schema.Fields() {
+		if !field.Leaf() || field.Repeated() {
+			slog.Warn("ignore nested or repeated parquet column", "column", field.Name())
+			continue
+		}
+		leaf, _ := schema.Lookup(field.Name())
+		reader.columns = append(reader.columns, ParquetColumn{Name: field.Name(), Index: leaf.ColumnIndex, Type: field.Type()})
+	}
+	return reader, nil
+}
+
+// Columns returns the column names, used as the header of the rows
+func (r *ParquetReader) Columns() []string {
+	var names = make([]string, len(r.columns))
+	for idx, column := range r.columns {
+		names[idx] = column.Name
+	}
+	return names
+}
+
+// Line returns the row number of the last read row
+func (r *ParquetReader) Line() int {
+	return r.line
+}
+
+func (r *ParquetReader) Read() ([]any, error) {
+	if len(r.pending) == 0 {
+		n, err := r.reader.ReadRows(r.rows)
+		if n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		r.pending = r.rows[:n]
+	}
+	row := r.pending[0]
+	r.pending = r.pending[1:]
+	r.line++
+
+	var byIndex = make(map[int]parquet.Value, len(row))
+	for _, value := range row {
+		byIndex[value.Column()] = value
+	}
+	var values = make([]any, len(r.columns))
+	for idx, column := range r.columns {
+		value, ok := byIndex[column.Index]
+		if !ok || value.IsNull() {
+			continue
+		}
+		var err error
+		if values[idx], err = parquetValue(column.Type, value); err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+	}
+	return values, nil
+}
+
+// parquetValue convert the parquet value by its physical and logical type
+func parquetValue(typ parquet.Type, value parquet.Value) (any, error) {
+	var logical = typ.LogicalType()
+	switch {
+	case logical != nil && logical.Timestamp != nil:
+		return time.Unix(0, parquetTimestamp(logical.Timestamp.Unit, value.Int64())).UTC(), nil
+	case logical != nil && logical.Date != nil:
+		return time.Unix(int64(value.Int32())*86400, 0).UTC(), nil
+	case logical != nil && logical.Decimal != nil:
+		return parquetDecimal(typ.Kind(), logical.Decimal, value)
+	}
+	switch typ.Kind() {
+	case parquet.Boolean:
+		return value.Boolean(), nil
+	case parquet.Int32:
+		if logical != nil && logical.Integer != nil && !logical.Integer.IsSigned {
+			return int64(uint32(value.Int32())), nil
+		}
+		return int64(value.Int32()), nil
+	case parquet.Int64:
+		return value.Int64(), nil
+	case parquet.Int96: // legacy timestamp, nanoseconds of the day and julian day
+		i96 := value.Int96()
+		nanos := int64(i96[1])<<32 | int64(i96[0])
+		return time.Unix(0, (int64(i96[2])-julianDayOfUnixEpoch)*int64(24*time.Hour)+nanos).UTC(), nil
+	case parquet.Float:
+		return float64(value.Float()), nil
+	case parquet.Double:
+		return value.Double(), nil
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(value.ByteArray()), nil
+	}
+	return nil, fmt.Errorf("unsupported parquet type %s", typ)
+}
+
+func parquetTimestamp(unit format.TimeUnit, v int64) int64 {
+	switch {
+	case unit.Millis != nil:
+		return v * int64(time.Millisecond)
+	case unit.Micros != nil:
+		return v * int64(time.Microsecond)
+	}
+	return v
+}
+
+// parquetDecimal convert the decimal to float, the unscaled value is an integer or a big-endian
+// two's complement byte array
+func parquetDecimal(kind parquet.Kind, decimal *format.DecimalType, value parquet.Value) (float64, error) {
+	var unscaled = new(big.Int)
+	switch kind {
+	case parquet.Int32:
+		unscaled.SetInt64(int64(value.Int32()))
+	case parquet.Int64:
+		unscaled.SetInt64(value.Int64())
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		b := value.ByteArray()
+		unscaled.SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 { // negative
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+	default:
+		return 0, fmt.Errorf("invalid physical type %s of decimal", kind)
+	}
+	f, _ := new(big.Float).SetInt(unscaled).Float64()
+	return f / math.Pow10(int(decimal.Scale)), nil
+}
+
+// parquetTime convert the value of time column to nanosecond, the integer is in --precision
+func (c *ImportCommand) parquetTime(value any) (int64, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixNano(), nil
+	case int64:
+		return v * c.cfg.TimeMultiplier, nil
+	case float64:
+		return int64(v * float64(c.cfg.TimeMultiplier)), nil
+	case string:
+		if checkIsNumber(v) {
+			return c.parseTimestamp2Int64(v), nil
+		}
+		tt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, err
+		}
+		return tt.UnixNano(), nil
+	}
+	return 0, fmt.Errorf("invalid time %v", value)
+}
+
+func parquetTagValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// validateParquetSchema check the columns of the parquet file are mapped by the csv options
+func (c *ImportCommand) validateParquetSchema() error {
+	file, err := os.Open(c.cfg.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	reader, err := NewParquetReader(file, stat.Size())
+	if err != nil {
+		return fmt.Errorf("open parquet file failed: %w", err)
+	}
+	fsm := &ImportFileFSM{staticTags: c.fsm.staticTags}
+	return fsm.parseCSVHeader(c.cfg, reader.Columns())
+}
+
+func (fsm *ImportFileFSM) processParquet(header []string, row []any) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		next, _ := fsm.processParquet(header, row) // never returns error in dml state
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			// the parquet columns are mapped by the csv options
+			if err := fsm.parseCSVHeader(command.cfg, header); err != nil {
+				fsm.database = ""
+				return err
+			}
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" {
+				return errors.New("database is required")
+			}
+			var measurement = fsm.measurement
+			if fsm.measurementField.Name != "" && row[fsm.measurementField.Pos] != nil {
+				if name := strings.TrimSpace(parquetTagValue(row[fsm.measurementField.Pos])); name != "" {
+					measurement = name
+				}
+			}
+			if measurement == "" {
+				return errors.New("measurement is required")
+			}
+			if row[fsm.timeField.Pos] == nil {
+				return errors.New("time is null")
+			}
+			timestamp, err := command.parquetTime(row[fsm.timeField.Pos])
+			if err != nil {
+				return fmt.Errorf("parse time failed: %w", err)
+			}
+			var point = &opengemini.Point{
+				Measurement: measurement,
+				Timestamp:   timestamp,
+				Tags:        make(map[string]string),
+				Fields:      make(map[string]interface{}),
+			}
+			for key, value := range fsm.staticTags {
+				point.Tags[key] = value
+			}
+			for key, pos := range fsm.tagMap {
+				if row[pos.Pos] != nil {
+					point.Tags[key] = parquetTagValue(row[pos.Pos])
+				}
+			}
+			for key, pos := range fsm.fieldMap {
+				switch value := row[pos.Pos].(type) {
+				case nil:
+				case time.Time:
+					point.Fields[key] = value.UnixNano()
+				default:
+					point.Fields[key] = value
+				}
+			}
+			if len(point.Fields) == 0 {
+				return errors.New("all fields of the row are null")
+			}
+			return command.appendPoint(ctx, point)
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
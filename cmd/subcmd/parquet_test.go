@@ -0,0 +1,98 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+type parquetTestRow struct {
+	Time  time.Time `parquet:"ts,timestamp(millisecond)"`
+	Host  string    `parquet:"host,dict"`
+	Price int64     `parquet:"price,decimal(2:18)"`
+	Usage *float64  `parquet:"usage,optional"`
+	Count int32     `parquet:"count"`
+	OK    bool      `parquet:"ok"`
+}
+
+func writeParquetTestFile(t *testing.T, rows []parquetTestRow) []byte {
+	var buf bytes.Buffer
+	writer := parquet.NewGenericWriter[parquetTestRow](&buf)
+	_, err := writer.Write(rows)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestParquetReader(t *testing.T) {
+	usage := 1.5
+	content := writeParquetTestFile(t, []parquetTestRow{
+		{Time: time.UnixMilli(1577836801001), Host: "h1", Price: -1234, Usage: &usage, Count: 3, OK: true},
+		{Time: time.UnixMilli(1577836802000), Host: "h1", Price: 5, Count: 4},
+	})
+	reader, err := NewParquetReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"ts", "host", "price", "usage", "count", "ok"}, reader.Columns())
+
+	row, err := reader.Read()
+	require.NoError(t, err)
+	require.Equal(t, []any{time.UnixMilli(1577836801001).UTC(), "h1", -12.34, 1.5, int64(3), true}, row)
+	row, err = reader.Read()
+	require.NoError(t, err)
+	require.Equal(t, []any{time.UnixMilli(1577836802000).UTC(), "h1", 0.05, nil, int64(4), false}, row)
+	require.Equal(t, 2, reader.Line())
+	_, err = reader.Read()
+	require.Error(t, err)
+}
+
+func TestProcessParquet(t *testing.T) {
+	usage := 1.5
+	content := writeParquetTestFile(t, []parquetTestRow{
+		{Time: time.UnixMilli(1577836801001), Host: "h1", Price: 1234, Usage: &usage, Count: 3, OK: true},
+		{Time: time.UnixMilli(1577836802000), Host: "h2", Price: 5, Count: 4},
+	})
+	command, client := newMockImportCommand(t, importFormatParquet, string(content))
+	command.cfg.Measurement = "cpu"
+	command.cfg.TimeField = "ts"
+	command.cfg.Tags = []string{"host"}
+	command.cfg.Drops = []string{"ok"}
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{
+		"cpu,host=h1 count=3i,price=12.34,usage=1.5 1577836801001000000",
+		"cpu,host=h2 count=4i,price=0.05 1577836802000000000",
+		"",
+	}, client.writes)
+}
+
+func TestProcessParquetInvalidSchema(t *testing.T) {
+	content := writeParquetTestFile(t, []parquetTestRow{{Time: time.UnixMilli(1577836801001), Host: "h1", Count: 3}})
+	command, client := newMockImportCommand(t, importFormatParquet, string(content))
+	command.cfg.Measurement = "cpu"
+	command.cfg.TimeField = "ts"
+	command.cfg.Tags = []string{"region"}
+	err := command.process()
+	requireExitCode(t, ExitCodeFailure, err)
+	require.ErrorContains(t, err, "tag name (region) not in csv header")
+	require.Empty(t, client.queries)
+
+	command, _ = newMockImportCommand(t, importFormatParquet, "not a parquet file")
+	requireExitCode(t, ExitCodeFailure, command.process())
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openGemini/openGemini-cli/core"
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// Prometheus text exposition and OpenMetrics format, see
+// https://prometheus.io/docs/instrumenting/exposition_formats/ and https://openmetrics.io/
+const (
+	promLabelName   = "__name__"
+	promFieldValue  = "value"
+	promTypeComment = "# TYPE "
+)
+
+// promSummarySuffixes the sample suffixes of histogram and summary family, the suffix without `_` is the field name
+var promSummarySuffixes = []string{"_bucket", "_sum", "_count", "_created", "_gcount", "_gsum"}
+
+// PromSample is a parsed sample line of text exposition format
+type PromSample struct {
+	Name      string
+	Labels    map[string]string
+	Value     float64
+	Timestamp int64 // nanosecond, core.NoTimestamp means absent
+}
+
+// parsePromSample parse line like `http_requests_total{method="post",code="200"} 1027 1395066363000`,
+// the exemplar of OpenMetrics behind ` # ` is ignored.
+func parsePromSample(line string) (*PromSample, error) {
+	var sample = &PromSample{Labels: make(map[string]string), Timestamp: core.NoTimestamp}
+	var i int
+	for i < len(line) && line[i] != '{' && line[i] != ' ' && line[i] != '\t' {
+		i++
+	}
+	sample.Name = line[:i]
+	if sample.Name == "" {
+		return nil, errors.New("missing metric name")
+	}
+	if i < len(line) && line[i] == '{' {
+		n, err := parsePromLabels(line[i+1:], sample.Labels)
+		if err != nil {
+			return nil, err
+		}
+		i += n + 1
+	}
+	rest := line[i:]
+	if idx := strings.Index(rest, " # "); idx != -1 { // exemplar
+		rest = rest[:idx]
+	}
+	parts := strings.Fields(rest)
+	if len(parts) == 0 || len(parts) > 2 {
+		return nil, fmt.Errorf("invalid sample %q", line)
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample value %q", parts[0])
+	}
+	sample.Value = value
+	if len(parts) == 2 {
+		sample.Timestamp, err = parsePromTimestamp(parts[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sample, nil
+}
+
+// parsePromLabels parse labels until `}`, returns the consumed length include `}`
+func parsePromLabels(s string, labels map[string]string) (int, error) {
+	var i int
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return 0, errors.New("unterminated label set")
+		}
+		if s[i] == '}' {
+			return i + 1, nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq == -1 {
+			return 0, errors.New("invalid label, missing '='")
+		}
+		key := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		if i >= len(s) || s[i] != '"' {
+			return 0, fmt.Errorf("invalid label %s, value must be quoted", key)
+		}
+		i++
+		var value strings.Builder
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return 0, fmt.Errorf("invalid label %s, unterminated value", key)
+		}
+		i++ // skip "
+		labels[key] = value.String()
+	}
+}
+
+// parsePromTimestamp parse the timestamp to nanosecond. Prometheus text format uses milliseconds,
+// OpenMetrics uses seconds with optional fraction, integer less than 1e11 is treated as seconds.
+func parsePromTimestamp(s string) (int64, error) {
+	if sec, frac, ok := strings.Cut(s, "."); ok && !strings.ContainsAny(s, "eE") && len(frac) <= 9 {
+		// avoid float precision loss of nanosecond
+		tsp, err := strconv.ParseInt(sec+frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		return tsp, nil
+	}
+	if strings.ContainsAny(s, ".eE") {
+		tsp, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		return int64(tsp * 1e9), nil
+	}
+	tsp, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	if tsp < 1e11 {
+		return tsp * 1e9, nil
+	}
+	return tsp * 1e6, nil
+}
+
+// skipValue returns true and count the sample if the value is NaN or Inf, which is not supported
+// by openGemini
+func (c *ImportCommand) skipValue(value float64, args ...any) bool {
+	if !math.IsNaN(value) && !math.IsInf(value, 0) {
+		return false
+	}
+	if c.stats.skipped == 0 {
+		slog.Warn("skip sample with unsupported value, NaN and Inf are not supported", args...)
+	}
+	c.stats.skipped++
+	return true
+}
+
+// parseDefaultTime parse --default-time as the timestamp of samples without timestamp,
+// support RFC3339 or number in --precision, returns the current time if not set.
+func (c *ImportCommand) parseDefaultTime() (int64, error) {
+	if c.cfg.DefaultTime == "" {
+		return time.Now().UnixNano(), nil
+	}
+	if tt, err := time.Parse(time.RFC3339Nano, c.cfg.DefaultTime); err == nil {
+		return tt.UnixNano(), nil
+	}
+	if tsp, err := strconv.ParseInt(c.cfg.DefaultTime, 10, 64); err == nil {
+		return tsp * c.cfg.TimeMultiplier, nil
+	}
+	return 0, fmt.Errorf("invalid --default-time %q, expect RFC3339 or epoch timestamp in --precision", c.cfg.DefaultTime)
+}
+
+// promFamily returns the metric family and the field name of the sample
+func (fsm *ImportFileFSM) promFamily(name string, fieldName string) (string, string) {
+	for _, suffix := range promSummarySuffixes {
+		family, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		switch fsm.promTypes[family] {
+		case "histogram", "summary", "gaugehistogram":
+			return family, strings.TrimPrefix(suffix, "_")
+		}
+	}
+	return name, fieldName
+}
+
+func (fsm *ImportFileFSM) processPromText(line string) (FSMCall, error) {
+	switch fsm.state {
+	case importStateDDL:
+		fsm.state = importStateDML
+		fsm.promTypes = make(map[string]string)
+		next, nextErr := fsm.processPromText(line)
+		return func(ctx context.Context, command *ImportCommand) error {
+			if err := command.createDatabase(ctx); err != nil {
+				return err
+			}
+			fsm.database = command.cfg.Database
+			fsm.retentionPolicy = command.cfg.RetentionPolicy
+			fsm.measurement = command.cfg.Measurement
+			var err error
+			if fsm.defaultTime, err = command.parseDefaultTime(); err != nil {
+				return err
+			}
+			if nextErr != nil {
+				return nextErr
+			}
+			return next(ctx, command)
+		}, nil
+	case importStateDML:
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return FSMCallEmpty, nil
+		}
+		if strings.HasPrefix(line, "#") {
+			if typ, ok := strings.CutPrefix(line, promTypeComment); ok {
+				if parts := strings.Fields(typ); len(parts) == 2 {
+					fsm.promTypes[parts[0]] = parts[1]
+				}
+			}
+			return FSMCallEmpty, nil // HELP, UNIT, EOF and comments
+		}
+		sample, err := parsePromSample(line)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, command *ImportCommand) error {
+			if fsm.database == "" {
+				return errors.New("database is required")
+			}
+			if command.skipValue(sample.Value, "sample", line) {
+				return nil
+			}
+			var fieldName = promFieldValue
+			if len(command.cfg.Fields) != 0 {
+				fieldName = command.cfg.Fields[0]
+			}
+			family, fieldName := fsm.promFamily(sample.Name, fieldName)
+			var point = &opengemini.Point{
+				Measurement: family,
+				Timestamp:   sample.Timestamp,
+				Tags:        sample.Labels,
+				Fields:      map[string]interface{}{fieldName: sample.Value},
+			}
+			if fsm.measurement != "" { // single measurement, the metric name as tag
+				point.Measurement = fsm.measurement
+				point.Tags[promLabelName] = family
+			}
+			if point.Timestamp == core.NoTimestamp {
+				point.Timestamp = fsm.defaultTime
+			}
+			for key, value := range fsm.staticTags {
+				point.Tags[key] = value
+			}
+			return command.appendPivotPoint(ctx, point)
+		}, nil
+	}
+	return FSMCallEmpty, nil
+}
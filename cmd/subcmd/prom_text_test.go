@@ -0,0 +1,106 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/openGemini/openGemini-cli/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePromSample(t *testing.T) {
+	sample, err := parsePromSample(`http_requests_total{method="post",path="/a\"b",} 1027 1395066363000`)
+	require.NoError(t, err)
+	require.Equal(t, &PromSample{
+		Name:      "http_requests_total",
+		Labels:    map[string]string{"method": "post", "path": `/a"b`},
+		Value:     1027,
+		Timestamp: 1395066363000000000,
+	}, sample)
+
+	sample, err = parsePromSample(`foo_bucket{le="0.5"} 3 1520879607.789 # {trace_id="abc"} 0.4 1520879607.7`)
+	require.NoError(t, err)
+	require.Equal(t, "0.5", sample.Labels["le"])
+	require.Equal(t, int64(1520879607789000000), sample.Timestamp)
+
+	sample, err = parsePromSample("metric_without_labels 12.47")
+	require.NoError(t, err)
+	require.Equal(t, 12.47, sample.Value)
+	require.Equal(t, core.NoTimestamp, sample.Timestamp)
+
+	for _, line := range []string{`foo{a="b" 1`, `foo{a=b} 1`, `foo abc`, `foo 1 2 3`} {
+		_, err = parsePromSample(line)
+		require.Error(t, err, line)
+	}
+}
+
+func TestProcessPromText(t *testing.T) {
+	content := `# HELP rpc_duration_seconds A summary of the RPC duration in seconds.
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773 1395066363000
+rpc_duration_seconds{quantile="0.9"} NaN 1395066363000
+rpc_duration_seconds_sum 1.7560473e+07 1395066363000
+rpc_duration_seconds_count 2693 1395066363000
+# TYPE up gauge
+up{job="node"} 1
+`
+	command, client := newMockImportCommand(t, importFormatPromText, content)
+	command.cfg.DefaultTime = "2014-03-17T14:26:03Z"
+	require.NoError(t, command.process())
+	require.Equal(t, 1, command.stats.skipped)
+
+	var lines []string
+	for _, line := range client.writes {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	require.Equal(t, []string{
+		// sum and count of the same series and time are pivoted into one point
+		"rpc_duration_seconds count=2693,sum=17560473 1395066363000000000",
+		"rpc_duration_seconds,quantile=0.5 value=4773 1395066363000000000",
+		"up,job=node value=1 1395066363000000000",
+	}, lines)
+}
+
+func TestProcessPromTextSingleMeasurement(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatPromText, "up{job=\"node\"} 1 1395066363000\n")
+	command.cfg.Measurement = "prometheus"
+	require.NoError(t, command.process())
+	require.Equal(t, "prometheus,__name__=up,job=node value=1 1395066363000000000", client.writes[0])
+}
+
+func TestProcessPromTextDefaultTime(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatPromText, "up 1\n")
+	command.cfg.Precision = "s"
+	require.NoError(t, command.cfg.configTimeMultiplier())
+	command.cfg.DefaultTime = "1395066363"
+	require.NoError(t, command.process())
+	require.Equal(t, "up value=1 1395066363000000000", client.writes[0])
+
+	// the explicit epoch timestamp is not replaced by the default time
+	command, client = newMockImportCommand(t, importFormatPromText, "up 1 0\n")
+	command.cfg.DefaultTime = "2014-03-17T14:26:03Z"
+	require.NoError(t, command.process())
+	require.Equal(t, "up value=1 0", client.writes[0])
+
+	command, client = newMockImportCommand(t, importFormatPromText, "up 1\n")
+	command.cfg.DefaultTime = "yesterday"
+	requireExitCode(t, ExitCodeFailure, command.process())
+	require.Empty(t, client.writes)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	importTransportAuto       = "auto"       // promremote for prometheus formats, otherwise http
+	importTransportHTTP       = "http"       // line protocol over http
+	importTransportPromRemote = "promremote" // prometheus remote write
+)
+
+// resolveTransport check --transport and resolve auto by the format, the column write protocol
+// is preferred if --column-write is specified
+func (c *ImportCommand) resolveTransport() error {
+	switch c.cfg.Transport {
+	case "", importTransportHTTP:
+		return nil
+	case importTransportAuto:
+		c.cfg.Transport = importTransportHTTP
+		if !c.cfg.ColumnWrite && (c.cfg.Format == importFormatJSONProm || c.cfg.Format == importFormatPromText) {
+			c.cfg.Transport = importTransportPromRemote
+		}
+	case importTransportPromRemote:
+		if c.cfg.ColumnWrite {
+			return errors.New("--transport promremote conflicts with --column-write")
+		}
+	default:
+		return fmt.Errorf("invalid --transport %s, only support auto, http, promremote", c.cfg.Transport)
+	}
+	if c.cfg.Transport == importTransportPromRemote {
+		slog.Info("write by prometheus remote write protocol")
+	}
+	return nil
+}
+
+// writePromRemote write the points by prometheus remote write, the samples of the same series
+// are merged, the field values must be numeric or boolean.
+func (c *ImportCommand) writePromRemote(ctx context.Context, points []*opengemini.Point) error {
+	var valueField = promFieldValue
+	if len(c.cfg.Fields) != 0 {
+		valueField = c.cfg.Fields[0]
+	}
+	var encode = func(points []*opengemini.Point) ([]byte, error) {
+		series, err := core.PromSeries(points, valueField)
+		if err != nil {
+			return nil, err
+		}
+		return core.EncodePromRemoteWrite(series), nil
+	}
+	body, err := encode(points)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, len(points), func(ctx context.Context, lo, hi int) error {
+		var payload = body
+		if lo != 0 || hi != len(points) { // the batch is split
+			if payload, err = encode(points[lo:hi]); err != nil {
+				return err
+			}
+		}
+		if err := c.throttle(ctx, hi-lo, len(payload)); err != nil {
+			return err
+		}
+		return c.httpClient.PromWrite(ctx, c.fsm.database, c.fsm.retentionPolicy, payload)
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func TestImportPromRemoteJsonP(t *testing.T) {
+	content := `{"status":"success","data":{"resultType":"matrix","result":[
+  {"metric":{"__name__":"up","job":"node"},"values":[[1435781430,"1"],[1435781445,"0"],[1435781445,"1"]]},
+  {"metric":{"__name__":"up","job":"prom"},"values":[[1435781430,"1"]]}
+]}}`
+	command, client := newMockImportCommand(t, importFormatJSONProm, content)
+	command.cfg.Transport = importTransportAuto
+	require.NoError(t, command.process())
+	require.Equal(t, importTransportPromRemote, command.cfg.Transport)
+	require.Empty(t, client.writes)
+	require.Equal(t, 1, client.writeCall)
+	require.Equal(t, []*core.PromTimeSeries{
+		{
+			Labels:  []core.PromLabel{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []core.PromSample{{Value: 1, Timestamp: 1435781430000}, {Value: 1, Timestamp: 1435781445000}},
+		},
+		{
+			Labels:  []core.PromLabel{{Name: "__name__", Value: "up"}, {Name: "job", Value: "prom"}},
+			Samples: []core.PromSample{{Value: 1, Timestamp: 1435781430000}},
+		},
+	}, client.promWrites)
+	require.Equal(t, 4, command.stats.written)
+}
+
+func TestImportPromRemotePromText(t *testing.T) {
+	content := `# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773 1395066363000
+rpc_duration_seconds_sum 1.7560473e+07 1395066363000
+rpc_duration_seconds_count 2693 1395066363000
+`
+	command, client := newMockImportCommand(t, importFormatPromText, content)
+	command.cfg.Transport = importTransportPromRemote
+	require.NoError(t, command.process())
+	var names []string
+	for _, series := range client.promWrites {
+		names = append(names, series.Labels[0].Value)
+	}
+	require.Equal(t, []string{"rpc_duration_seconds", "rpc_duration_seconds_count", "rpc_duration_seconds_sum"}, names)
+}
+
+func TestImportTransport(t *testing.T) {
+	// the other formats and the column write protocol keep their transport
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.Transport = importTransportAuto
+	require.NoError(t, command.process())
+	require.Equal(t, importTransportHTTP, command.cfg.Transport)
+	require.Equal(t, "a.b value=1 1000000000", client.writes[0])
+
+	command, _ = newMockImportCommand(t, importFormatJSONProm, "")
+	command.cfg.Transport = importTransportAuto
+	command.cfg.ColumnWrite = true
+	require.NoError(t, command.resolveTransport())
+	require.Equal(t, importTransportHTTP, command.cfg.Transport)
+
+	command, _ = newMockImportCommand(t, importFormatJSONProm, "")
+	command.cfg.Transport = importTransportPromRemote
+	command.cfg.ColumnWrite = true
+	requireExitCode(t, ExitCodeFailure, command.process())
+
+	command, _ = newMockImportCommand(t, importFormatJSONProm, "")
+	command.cfg.Transport = "udp"
+	requireExitCode(t, ExitCodeFailure, command.process())
+
+	command, client = newMockImportCommand(t, importFormatLineProtocol, "# DML\n# CONTEXT-DATABASE: db0\ncpu v=1i 1000000\n")
+	command.cfg.Transport = importTransportPromRemote
+	require.NoError(t, command.process())
+	require.Equal(t, []core.PromSample{{Value: 1, Timestamp: 1}}, client.promWrites[0].Samples)
+
+	// the string values are not supported by remote write
+	command, _ = newMockImportCommand(t, importFormatLineProtocol, "# DML\n# CONTEXT-DATABASE: db0\ncpu v=\"x\" 1\n")
+	command.cfg.Transport = importTransportPromRemote
+	requireExitCode(t, ExitCodeParseError, command.process())
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// the units of influxql duration literal, the longer unit is matched first
+var durationUnits = []struct {
+	unit     string
+	duration time.Duration
+}{
+	{"ns", time.Nanosecond},
+	{"us", time.Microsecond},
+	{"µ", time.Microsecond},
+	{"u", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+}
+
+// parseRPDuration parse the influxql duration literal such as `30d` or `1h30m`, INF means infinite
+// and is returned as 0
+func parseRPDuration(s string) (time.Duration, error) {
+	if strings.EqualFold(s, "INF") || s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+	var total time.Duration
+	var rest = s
+	for rest != "" {
+		idx := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if idx <= 0 {
+			return 0, fmt.Errorf("invalid duration %s", s)
+		}
+		n, err := strconv.ParseInt(rest[:idx], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s: %w", s, err)
+		}
+		rest = rest[idx:]
+		var matched bool
+		for _, u := range durationUnits {
+			if strings.HasPrefix(rest, u.unit) {
+				total += time.Duration(n) * u.duration
+				rest = rest[len(u.unit):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return 0, fmt.Errorf("invalid duration %s, unknown unit", s)
+		}
+	}
+	return total, nil
+}
+
+// validateCreateRP check the --create-rp and --rp-* options
+func (c *ImportCommand) validateCreateRP() error {
+	if c.cfg.CreateRP == "" {
+		if c.cfg.RPDuration != "" || c.cfg.RPShardDuration != "" || c.cfg.RPReplication != 0 || c.cfg.RPDefault || c.cfg.RPAlter {
+			return errors.New("--rp-duration, --rp-shard-duration, --rp-replication, --rp-default and --rp-alter require --create-rp")
+		}
+		return nil
+	}
+	if c.cfg.RPReplication < 0 {
+		return errors.New("--rp-replication must not be negative")
+	}
+	for _, duration := range []string{c.cfg.RPDuration, c.cfg.RPShardDuration} {
+		if duration == "" {
+			continue
+		}
+		if _, err := parseRPDuration(duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDatabase create the database of --database and the retention policy of --create-rp if they
+// do not exist, every database is checked once
+func (c *ImportCommand) createDatabase(ctx context.Context) error {
+	if err := c.ensureDatabase(ctx, c.cfg.Database); err != nil {
+		return err
+	}
+	return c.ensureRetentionPolicy(ctx, c.cfg.Database)
+}
+
+// ensureDatabase create the database if it does not exist
+func (c *ImportCommand) ensureDatabase(ctx context.Context, database string) error {
+	if c.preflight != nil || database == "" || c.provisioned[database] {
+		return nil
+	}
+	databases, err := c.showDatabases(ctx)
+	if err != nil {
+		slog.Error("show databases failed", "reason", err)
+		return &importDDLError{command: "SHOW DATABASES", err: err}
+	}
+	if _, ok := databases[database]; ok {
+		slog.Info("database already exists", "database", database)
+	} else {
+		if err = c.executeDDL(ctx, "CREATE DATABASE "+quoteIdentifier(database)); err != nil {
+			return err
+		}
+		slog.Info("database created", "database", database)
+	}
+	c.markProvisioned(database)
+	return nil
+}
+
+// ensureRetentionPolicy create the retention policy of --create-rp on the database if it does not
+// exist, the existing one whose options differ from the specified ones is altered only with --rp-alter
+func (c *ImportCommand) ensureRetentionPolicy(ctx context.Context, database string) error {
+	var key = database + "." + c.cfg.CreateRP
+	if c.preflight != nil || c.cfg.CreateRP == "" || database == "" || c.provisioned[key] {
+		return nil
+	}
+	rp, err := c.fetchRetentionPolicy(ctx, database, c.cfg.CreateRP)
+	if err != nil {
+		slog.Error("show retention policies failed", "database", database, "reason", err)
+		return &importDDLError{command: "SHOW RETENTION POLICIES ON " + quoteIdentifier(database), err: err}
+	}
+	var target = quoteIdentifier(c.cfg.CreateRP) + " ON " + quoteIdentifier(database)
+	switch options := c.retentionPolicyOptions(rp); {
+	case rp == nil:
+		if err = c.executeDDL(ctx, "CREATE RETENTION POLICY "+target+c.retentionPolicyOptions(nil)); err != nil {
+			return err
+		}
+		slog.Info("retention policy created", "database", database, "retention_policy", c.cfg.CreateRP)
+	case options != "" && !c.cfg.RPAlter:
+		slog.Warn("retention policy already exists with different options, it is left untouched, use --rp-alter to alter it",
+			"database", database, "retention_policy", c.cfg.CreateRP, "options", strings.TrimSpace(options))
+	case options != "":
+		if err = c.executeDDL(ctx, "ALTER RETENTION POLICY "+target+options); err != nil {
+			return err
+		}
+		slog.Info("retention policy altered", "database", database, "retention_policy", c.cfg.CreateRP)
+	default:
+		slog.Info("retention policy already exists", "database", database, "retention_policy", c.cfg.CreateRP)
+	}
+	// the cached retention policy of --retention-check is stale
+	delete(c.retentionPolicies, key)
+	c.markProvisioned(key)
+	return nil
+}
+
+// retentionPolicyOptions returns the clauses of the options differ from the existing retention
+// policy, all specified options are returned if it is nil
+func (c *ImportCommand) retentionPolicyOptions(rp *retentionPolicy) string {
+	var clauses []string
+	if c.cfg.RPDuration != "" {
+		duration, _ := parseRPDuration(c.cfg.RPDuration)
+		if rp == nil || rp.duration != duration {
+			clauses = append(clauses, "DURATION "+c.cfg.RPDuration)
+		}
+	} else if rp == nil {
+		clauses = append(clauses, "DURATION INF") // DURATION is required by CREATE RETENTION POLICY
+	}
+	if c.cfg.RPReplication > 0 && (rp == nil || rp.replicaN != c.cfg.RPReplication) {
+		clauses = append(clauses, "REPLICATION "+strconv.Itoa(c.cfg.RPReplication))
+	} else if rp == nil {
+		clauses = append(clauses, "REPLICATION 1")
+	}
+	if c.cfg.RPShardDuration != "" {
+		duration, _ := parseRPDuration(c.cfg.RPShardDuration)
+		if rp == nil || rp.shardGroupDuration != duration {
+			clauses = append(clauses, "SHARD DURATION "+c.cfg.RPShardDuration)
+		}
+	}
+	if c.cfg.RPDefault && (rp == nil || !rp.isDefault) {
+		clauses = append(clauses, "DEFAULT")
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " " + strings.Join(clauses, " ")
+}
+
+func (c *ImportCommand) markProvisioned(key string) {
+	if c.provisioned == nil {
+		c.provisioned = make(map[string]bool)
+	}
+	c.provisioned[key] = true
+}
+
+// showDatabases returns the names of databases by `SHOW DATABASES`
+func (c *ImportCommand) showDatabases(ctx context.Context) (map[string]struct{}, error) {
+	result, err := c.httpClient.Query(ctx, &opengemini.Query{Command: "SHOW DATABASES"})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	var databases = make(map[string]struct{})
+	for _, res := range result.Results {
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		for _, series := range res.Series {
+			for _, value := range series.Values {
+				if len(value) == 0 {
+					continue
+				}
+				if name, ok := value[0].(string); ok {
+					databases[name] = struct{}{}
+				}
+			}
+		}
+	}
+	return databases, nil
+}
+
+// toInt converts the number of query result to int
+func toInt(value any) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	}
+	return 0
+}
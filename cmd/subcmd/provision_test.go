@@ -0,0 +1,155 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRPDuration(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"1h30m": 90 * time.Minute,
+		"2w":    14 * 24 * time.Hour,
+		"100ms": 100 * time.Millisecond,
+		"INF":   0,
+		"0":     0,
+	} {
+		duration, err := parseRPDuration(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, duration, s)
+	}
+	for _, s := range []string{"", "d", "30", "30y", "1.5h"} {
+		_, err := parseRPDuration(s)
+		require.Error(t, err, s)
+	}
+}
+
+// mockCluster responds SHOW DATABASES and SHOW RETENTION POLICIES by the created ones
+func mockCluster(databases []string, rps [][]any) func(command string) *opengemini.QueryResult {
+	return func(command string) *opengemini.QueryResult {
+		var series *opengemini.Series
+		switch {
+		case command == "SHOW DATABASES":
+			series = &opengemini.Series{Columns: []string{"name"}}
+			for _, database := range databases {
+				series.Values = append(series.Values, opengemini.SeriesValue{database})
+			}
+		case strings.HasPrefix(command, "SHOW RETENTION POLICIES"):
+			series = &opengemini.Series{Columns: []string{"name", "duration", "shardGroupDuration", "hot duration", "warm duration", "index duration", "replicaN", "default"}}
+			for _, rp := range rps {
+				series.Values = append(series.Values, rp)
+			}
+		default:
+			return &opengemini.QueryResult{}
+		}
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Series: []*opengemini.Series{series}}}}
+	}
+}
+
+func TestImportCreateRP(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RetentionPolicy = "rp0"
+	command.cfg.RPDuration = "30d"
+	command.cfg.RPReplication = 2
+	command.cfg.RPShardDuration = "1d"
+	command.cfg.RPDefault = true
+	client.responder = mockCluster(nil, nil)
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		"SHOW DATABASES",
+		`CREATE DATABASE "db0"`,
+		`SHOW RETENTION POLICIES ON "db0"`,
+		`CREATE RETENTION POLICY "rp0" ON "db0" DURATION 30d REPLICATION 2 SHARD DURATION 1d DEFAULT`,
+	}, client.queries)
+	require.Equal(t, 1, command.stats.written)
+
+	// the existing database and retention policy are kept
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RPDuration = "30d"
+	client.responder = mockCluster([]string{"db0"}, [][]any{
+		{"autogen", "0s", "168h0m0s", "0s", "0s", "0s", float64(1), false},
+		{"rp0", "720h0m0s", "24h0m0s", "0s", "0s", "0s", float64(2), true},
+	})
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `SHOW RETENTION POLICIES ON "db0"`}, client.queries)
+
+	// the options differ from the existing retention policy are reported and altered only with --rp-alter
+	existing := [][]any{{"rp0", "720h0m0s", "24h0m0s", "0s", "0s", "0s", float64(2), false}}
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RPDuration = "7d"
+	client.responder = mockCluster([]string{"db0"}, existing)
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"SHOW DATABASES", `SHOW RETENTION POLICIES ON "db0"`}, client.queries)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RPDuration = "7d"
+	command.cfg.RPReplication = 2
+	command.cfg.RPDefault = true
+	command.cfg.RPAlter = true
+	client.responder = mockCluster([]string{"db0"}, existing)
+	require.NoError(t, command.process())
+	require.Equal(t, `ALTER RETENTION POLICY "rp0" ON "db0" DURATION 7d DEFAULT`, client.queries[len(client.queries)-1])
+}
+
+func TestImportCreateRPLineProtocol(t *testing.T) {
+	content := `# DDL
+CREATE DATABASE db0
+# DML
+# CONTEXT-DATABASE: db0
+cpu v=1 1
+# CONTEXT-DATABASE: db0
+cpu v=2 2
+`
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RetentionPolicy = "rp0"
+	client.responder = mockCluster([]string{"db0"}, nil)
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		"CREATE DATABASE db0",
+		`SHOW RETENTION POLICIES ON "db0"`,
+		`CREATE RETENTION POLICY "rp0" ON "db0" DURATION INF REPLICATION 1`,
+	}, client.queries)
+	// the points are written to --retention-policy instead of autogen
+	require.Equal(t, "rp0", command.fsm.retentionPolicy)
+}
+
+func TestImportCreateRPInvalid(t *testing.T) {
+	command, _ := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.RPDuration = "30d"
+	requireExitCode(t, ExitCodeFailure, command.process())
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	command.cfg.RPShardDuration = "1 day"
+	requireExitCode(t, ExitCodeFailure, command.process())
+
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.CreateRP = "rp0"
+	client.responder = func(command string) *opengemini.QueryResult {
+		return &opengemini.QueryResult{Error: "authorization failed"}
+	}
+	requireExitCode(t, ExitCodeDDLError, command.process())
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// controlPollInterval the interval of checking the modification of control file
+var controlPollInterval = time.Second
+
+// tokenBucket allows rate tokens per second with a burst of one second, the request larger than
+// the burst is allowed by borrowing the tokens of the future.
+type tokenBucket struct {
+	rate   float64 // tokens per second, no limit if it is not positive
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.tokens = b.rate
+	} else {
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.rate)
+	}
+	b.last = now
+}
+
+// reserve take n tokens and returns the duration to wait for them
+func (b *tokenBucket) reserve(now time.Time, n int) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(now)
+	b.tokens -= float64(n)
+	return b.delay(now)
+}
+
+// delay returns the duration to wait until the borrowed tokens are refilled
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(now)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// setRate change the rate at now, the elapsed time before it is refilled by the old rate
+func (b *tokenBucket) setRate(now time.Time, rate float64) {
+	if b.rate == rate {
+		return
+	}
+	if b.rate > 0 && !b.last.IsZero() {
+		b.refill(now)
+	}
+	b.rate = rate
+	b.tokens = min(b.tokens, rate)
+}
+
+// RateLimit is the limits of writing, 0 means unlimited
+type RateLimit struct {
+	MaxPointsPerSecond int `json:"max_points_per_second"`
+	MaxBytesPerSecond  int `json:"max_bytes_per_second"`
+}
+
+// rateLimiter limits the points and bytes written per second, the limits can be changed while
+// the writes are waiting.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   RateLimit
+	points  tokenBucket
+	bytes   tokenBucket
+	changed chan struct{} // closed and replaced when the limits are changed
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	l := new(rateLimiter)
+	l.setLimit(limit)
+	return l
+}
+
+func (l *rateLimiter) setLimit(limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.limit = limit
+	l.points.setRate(now, float64(limit.MaxPointsPerSecond))
+	l.bytes.setRate(now, float64(limit.MaxBytesPerSecond))
+	if l.changed != nil {
+		close(l.changed) // wake up the waiting writes
+	}
+	l.changed = make(chan struct{})
+}
+
+func (l *rateLimiter) Limit() RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// wait until the points and bytes are allowed to write, the delay is computed again by the new
+// limits if they are changed while waiting
+func (l *rateLimiter) wait(ctx context.Context, points, bytes int) error {
+	l.mu.Lock()
+	now := time.Now()
+	delay := max(l.points.reserve(now, points), l.bytes.reserve(now, bytes))
+	changed := l.changed
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	slog.Debug("write is throttled", "points", points, "bytes", bytes, "delay", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-changed:
+			l.mu.Lock()
+			now = time.Now()
+			delay = max(l.points.delay(now), l.bytes.delay(now))
+			changed = l.changed
+			l.mu.Unlock()
+			if delay <= 0 {
+				return nil
+			}
+			timer.Reset(delay)
+		}
+	}
+}
+
+// throttle wait for the rate limits before writing, the waiting time is excluded from the write latency
+func (c *ImportCommand) throttle(ctx context.Context, points, bytes int) error {
+	if c.limiter == nil {
+		return nil
+	}
+	start := time.Now()
+	defer func() { c.throttled += time.Since(start) }()
+	return c.limiter.wait(ctx, points, bytes)
+}
+
+// loadControlFile read the rate limits of the control file, the missing keys keep the current limits
+func (c *ImportCommand) loadControlFile() error {
+	content, err := os.ReadFile(c.cfg.ControlFile)
+	if err != nil {
+		return err
+	}
+	var limit = c.limiter.Limit()
+	if err = json.Unmarshal(content, &limit); err != nil {
+		return fmt.Errorf("invalid control file %s: %w", c.cfg.ControlFile, err)
+	}
+	if limit.MaxPointsPerSecond < 0 || limit.MaxBytesPerSecond < 0 {
+		return fmt.Errorf("invalid control file %s: the limits must not be negative", c.cfg.ControlFile)
+	}
+	if limit != c.limiter.Limit() {
+		c.limiter.setLimit(limit)
+		slog.Info("rate limits changed", "max_points_per_second", limit.MaxPointsPerSecond, "max_bytes_per_second", limit.MaxBytesPerSecond)
+	}
+	return nil
+}
+
+// watchControlFile reload the control file when it is modified or SIGHUP is received, until ctx is done
+func (c *ImportCommand) watchControlFile(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	ticker := time.NewTicker(controlPollInterval)
+	defer ticker.Stop()
+
+	var modTime time.Time
+	var reload = func(force bool) {
+		stat, err := os.Stat(c.cfg.ControlFile)
+		if err != nil || (!force && stat.ModTime().Equal(modTime)) {
+			return
+		}
+		modTime = stat.ModTime()
+		if err = c.loadControlFile(); err != nil {
+			slog.Error("load control file failed", "reason", err)
+		}
+	}
+	reload(false)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload(true)
+		case <-ticker.C:
+			reload(false)
+		}
+	}
+}
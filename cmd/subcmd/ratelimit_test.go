@@ -0,0 +1,96 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	var now = time.Unix(0, 0)
+	var bucket = tokenBucket{rate: 100}
+	require.Equal(t, time.Duration(0), bucket.reserve(now, 100)) // the burst
+	require.Equal(t, 500*time.Millisecond, bucket.reserve(now, 50))
+	require.Equal(t, time.Second, bucket.reserve(now.Add(500*time.Millisecond), 100))
+	require.Equal(t, time.Duration(0), bucket.reserve(now.Add(3*time.Second), 100))
+
+	// the borrowed tokens are refilled by the new rate after it is changed
+	require.Equal(t, 2*time.Second, bucket.reserve(now.Add(3*time.Second), 200))
+	bucket.setRate(now.Add(4*time.Second), 200)
+	require.Equal(t, 500*time.Millisecond, bucket.delay(now.Add(4*time.Second)))
+
+	bucket.setRate(now.Add(5*time.Second), 0)
+	require.Equal(t, time.Duration(0), bucket.reserve(now.Add(5*time.Second), 1000))
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{MaxBytesPerSecond: 1000})
+	require.NoError(t, limiter.wait(context.Background(), 1, 1000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, limiter.wait(ctx, 1, 1000), context.Canceled)
+
+	limiter.setLimit(RateLimit{})
+	start := time.Now()
+	require.NoError(t, limiter.wait(context.Background(), 1000, 1000000))
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestRateLimiterChangedWhileWaiting(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{MaxPointsPerSecond: 10})
+	time.AfterFunc(50*time.Millisecond, func() { limiter.setLimit(RateLimit{MaxPointsPerSecond: 1000}) })
+	start := time.Now()
+	require.NoError(t, limiter.wait(context.Background(), 30, 1)) // 2 seconds by the old limit
+	require.Less(t, time.Since(start), time.Second)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestImportControlFile(t *testing.T) {
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\na.c 2 1\n")
+	command.cfg.ControlFile = filepath.Join(t.TempDir(), "control.json")
+	command.limiter = newRateLimiter(RateLimit{MaxPointsPerSecond: 10})
+
+	require.NoError(t, os.WriteFile(command.cfg.ControlFile, []byte(`{"max_bytes_per_second":4096}`), 0600))
+	require.NoError(t, command.loadControlFile())
+	require.Equal(t, RateLimit{MaxPointsPerSecond: 10, MaxBytesPerSecond: 4096}, command.limiter.Limit())
+
+	require.NoError(t, os.WriteFile(command.cfg.ControlFile, []byte(`{"max_points_per_second":-1}`), 0600))
+	require.Error(t, command.loadControlFile())
+	require.Equal(t, RateLimit{MaxPointsPerSecond: 10, MaxBytesPerSecond: 4096}, command.limiter.Limit())
+
+	poll := controlPollInterval
+	controlPollInterval = 10 * time.Millisecond
+	defer func() { controlPollInterval = poll }()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		command.watchControlFile(ctx)
+		close(done)
+	}()
+	require.NoError(t, os.WriteFile(command.cfg.ControlFile, []byte(`{"max_points_per_second":0,"max_bytes_per_second":0}`), 0600))
+	require.Eventually(t, func() bool { return command.limiter.Limit() == RateLimit{} }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	require.NoError(t, command.process())
+	require.Equal(t, 1, client.writeCall)
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+// sortByMeasurement group the points by measurement, the points of the same measurement keep their order
+func sortByMeasurement(points []*opengemini.Point) {
+	var less = func(i, j int) bool { return points[i].Measurement < points[j].Measurement }
+	if !sort.SliceIsSorted(points, less) {
+		sort.SliceStable(points, less)
+	}
+}
+
+// rejectPoints count the points rejected by openGemini after they are isolated, and append them
+// to --reject-file in line protocol, so that they can be fixed and imported again
+func (c *ImportCommand) rejectPoints(points []*opengemini.Point, cause error) {
+	if c.stats.failures == nil {
+		c.stats.failures = make(map[string]int)
+	}
+	for _, point := range points {
+		c.stats.failures[point.Measurement]++
+	}
+	c.stats.rejectedPoints += len(points)
+	if c.cfg.RejectFile == "" {
+		slog.Warn("points are rejected, keep them by --reject-file", "points", len(points), "reason", cause)
+		return
+	}
+	lines, err := core.EncodeLineProtocol(points)
+	if err != nil {
+		slog.Error("encode rejected points failed", "reason", err)
+		return
+	}
+	if err = appendRejectFile(c.cfg.RejectFile, c.fsm.database, c.fsm.retentionPolicy, lines); err != nil {
+		slog.Error("write reject file failed", "file", c.cfg.RejectFile, "reason", err)
+	}
+}
+
+// appendRejectFile append the lines with the context of database and retention policy, the file is an
+// importable line protocol file
+func appendRejectFile(path, database, retentionPolicy, lines string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	var builder strings.Builder
+	if stat.Size() == 0 {
+		builder.WriteString(importTokenDML + "\n")
+	}
+	fmt.Fprintf(&builder, "%s %s\n%s %s\n", importTokenDatabase, database, importTokenRetentionPolicy, retentionPolicy)
+	builder.WriteString(lines)
+	if _, err = file.WriteString(builder.String()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// failureSummary returns the rejected points by measurement like `cpu=3,mem=1`
+func (s *importStats) failureSummary() string {
+	var measurements = make([]string, 0, len(s.failures))
+	for measurement := range s.failures {
+		measurements = append(measurements, measurement)
+	}
+	sort.Strings(measurements)
+	var parts = make([]string, 0, len(measurements))
+	for _, measurement := range measurements {
+		parts = append(parts, fmt.Sprintf("%s=%d", measurement, s.failures[measurement]))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openGemini/opengemini-client-go/proto"
+	"github.com/stretchr/testify/require"
+)
+
+const partialWriteContent = `{"results":[{"statement_id":0,"series":[
+  {"name":"cpu","columns":["time","v"],"values":[[1,1],[3,2]]},
+  {"name":"bad","columns":["time","v"],"values":[[2,1],[4,2]]},
+  {"name":"cpu","columns":["time","v"],"values":[[5,3]]}
+]}]}
+`
+
+// partialResponder rejects the records of measurement bad, the request only with them is failed
+// if whole is true, otherwise it is partially written
+func partialResponder(whole bool) func(in *proto.WriteRequest) proto.ResponseCode {
+	return func(in *proto.WriteRequest) proto.ResponseCode {
+		for _, record := range in.Records {
+			if record.Measurement != "bad" {
+				continue
+			}
+			if whole && len(in.Records) == 1 {
+				return proto.ResponseCode_Failed
+			}
+			return proto.ResponseCode_Partial
+		}
+		return proto.ResponseCode_Success
+	}
+}
+
+func TestImportPartialWrite(t *testing.T) {
+	for _, whole := range []bool{true, false} {
+		command, _ := newMockImportCommand(t, importFormatJSONInflux, partialWriteContent)
+		command.cfg.ColumnWrite = true
+		command.cfg.RejectFile = filepath.Join(t.TempDir(), "reject.lp")
+		writeClient := command.writeClient.(*mockWriteClient)
+		writeClient.responder = partialResponder(whole)
+		requireExitCode(t, ExitCodePartial, command.process())
+
+		require.Equal(t, 3, command.stats.written)
+		require.Equal(t, 2, command.stats.rejectedPoints)
+		require.Equal(t, "bad=2", command.stats.failureSummary())
+		require.Equal(t, 1, command.stats.rejectedErrors)
+		require.Len(t, writeClient.requests, 1) // only the failed subset is written again
+		require.Equal(t, "cpu", writeClient.requests[0].Records[0].Measurement)
+
+		// the reject file is importable
+		reimport, client := newMockImportCommand(t, importFormatLineProtocol, "")
+		reimport.cfg.Path = command.cfg.RejectFile
+		require.NoError(t, reimport.process())
+		require.Equal(t, []string{"bad v=1i 2", "bad v=2i 4"}, nonEmpty(client.writes))
+	}
+}
+
+func TestImportPartialWriteWithoutRejectFile(t *testing.T) {
+	command, _ := newMockImportCommand(t, importFormatJSONInflux, partialWriteContent)
+	command.cfg.ColumnWrite = true
+	command.cfg.OnError = importOnErrorRetry
+	command.writeClient.(*mockWriteClient).responder = partialResponder(false)
+	requireExitCode(t, ExitCodePartial, command.process())
+	require.Equal(t, 3, command.stats.written)
+	require.Equal(t, 2, command.stats.rejectedPoints)
+}
+
+func TestImportRejectLineProtocol(t *testing.T) {
+	const content = "# DML\n# CONTEXT-DATABASE: db0\ncpu,host=a usage=0.5 1\nbad,host=a count=3i,usage=1.5,ok=t,note=\"x y\" 2\n"
+	command, _ := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.ColumnWrite = true
+	command.cfg.RejectFile = filepath.Join(t.TempDir(), "reject.lp")
+	command.writeClient.(*mockWriteClient).responder = partialResponder(true)
+	requireExitCode(t, ExitCodePartial, command.process())
+
+	// the rejected line keeps the field types of input
+	reimport, client := newMockImportCommand(t, importFormatLineProtocol, "")
+	reimport.cfg.Path = command.cfg.RejectFile
+	require.NoError(t, reimport.process())
+	require.Equal(t, []string{`bad,host=a count=3i,note="x y",ok=true,usage=1.5 2`}, nonEmpty(client.writes))
+}
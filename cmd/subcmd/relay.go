@@ -0,0 +1,657 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/proto"
+
+	"github.com/openGemini/openGemini-cli/common"
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	relayMaxLineSize   = 1 << 20
+	relayMaxPacketSize = 64 * 1024
+	relayMaxBodySize   = 32 << 20 // both the request body and the decompressed one
+	relaySpoolSuffix   = ".lp"
+)
+
+var errRelaySpoolFull = errors.New("spool is full, --spool-max-size is reached")
+
+type RelayConfig struct {
+	*core.CommandLineConfig
+	HTTPListen      string
+	TCPListen       string
+	UDPListen       string
+	ColumnWrite     bool
+	ColumnWritePort int
+	BatchSize       int
+	FlushInterval   time.Duration
+	SpoolDir        string
+	SpoolMaxSize    int64
+}
+
+// relayKey is the write target of the lines
+type relayKey struct {
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retention_policy"`
+	Precision       string `json:"precision"`
+}
+
+type relayBatch struct {
+	relayKey
+	lines []string
+	acks  []*relayAck // the http requests waiting for the lines
+}
+
+// relayAck replies the http request once all the batches holding its lines are forwarded or spooled
+type relayAck struct {
+	batches int
+	err     error
+	done    chan error
+}
+
+func (a *relayAck) finish(err error) {
+	if a.err == nil {
+		a.err = err
+	}
+	a.batches--
+	if a.batches == 0 {
+		a.done <- a.err
+	}
+}
+
+// RelayCommand receives line protocol by http `/write`, tcp and udp, batches them by database,
+// retention policy and precision, and forwards them to openGemini. The batch failed to forward
+// is spooled to disk and forwarded again when the upstream is available.
+type RelayCommand struct {
+	cfg         *RelayConfig
+	httpClient  core.HttpClient
+	writeClient proto.WriteServiceClient
+	encoder     *columnEncoder
+
+	incoming chan *relayBatch
+	batches  map[relayKey]*relayBatch
+	closers  []io.Closer
+	addrs    map[string]net.Addr // the listening addresses by protocol
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{} // the accepted tcp connections
+	spoolSeq int
+
+	// the spool files and their total size, they are only accessed by the batching goroutine
+	spoolCount int
+	spoolSize  int64
+}
+
+func (c *RelayCommand) Run(config *RelayConfig) error {
+	if config.BatchSize <= 0 {
+		config.BatchSize = common.DefaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	httpClient, err := core.NewHttpClient(config.CommandLineConfig)
+	if err != nil {
+		slog.Error("create http client failed", "reason", err)
+		return err
+	}
+	c.httpClient = httpClient
+	if config.ColumnWrite {
+		c.writeClient, err = NewColumnWriterClient(&ImportConfig{CommandLineConfig: config.CommandLineConfig, ColumnWritePort: config.ColumnWritePort})
+		if err != nil {
+			slog.Error("create column writer client failed", "reason", err)
+			return err
+		}
+	}
+	c.cfg = config
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err = c.start(); err != nil {
+		return err
+	}
+	c.run(ctx)
+	return nil
+}
+
+// start listen on the configured addresses
+func (c *RelayCommand) start() error {
+	if c.cfg.HTTPListen == "" && c.cfg.TCPListen == "" && c.cfg.UDPListen == "" {
+		return errors.New("at least one of --http-listen, --tcp-listen and --udp-listen is required")
+	}
+	if c.cfg.SpoolDir != "" {
+		if err := os.MkdirAll(c.cfg.SpoolDir, 0700); err != nil {
+			return err
+		}
+		c.loadSpool()
+	}
+	c.incoming = make(chan *relayBatch, 1024)
+	c.batches = make(map[relayKey]*relayBatch)
+	c.addrs = make(map[string]net.Addr)
+	c.conns = make(map[net.Conn]struct{})
+	var startErr error
+	if c.cfg.HTTPListen != "" {
+		listener, err := net.Listen("tcp", c.cfg.HTTPListen)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: c.httpHandler(), ReadHeaderTimeout: 10 * time.Second}
+		c.addrs["http"] = listener.Addr()
+		c.closers = append(c.closers, relayServerCloser{server})
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("http listener stopped", "reason", err)
+			}
+		}()
+		slog.Info("relay listening", "protocol", "http", "address", listener.Addr())
+	}
+	if c.cfg.TCPListen != "" {
+		listener, err := net.Listen("tcp", c.cfg.TCPListen)
+		if err != nil {
+			startErr = err
+		} else {
+			c.addrs["tcp"] = listener.Addr()
+			c.closers = append(c.closers, listener)
+			c.wg.Add(1)
+			go c.serveTCP(listener)
+			slog.Info("relay listening", "protocol", "tcp", "address", listener.Addr())
+		}
+	}
+	if c.cfg.UDPListen != "" && startErr == nil {
+		conn, err := net.ListenPacket("udp", c.cfg.UDPListen)
+		if err != nil {
+			startErr = err
+		} else {
+			c.addrs["udp"] = conn.LocalAddr()
+			c.closers = append(c.closers, conn)
+			c.wg.Add(1)
+			go c.serveUDP(conn)
+			slog.Info("relay listening", "protocol", "udp", "address", conn.LocalAddr())
+		}
+	}
+	if startErr != nil {
+		c.stop()
+		return startErr
+	}
+	return nil
+}
+
+// relayServerCloser shutdown the http server gracefully, so that no request is in flight after it is closed
+type relayServerCloser struct {
+	server *http.Server
+}
+
+func (s relayServerCloser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// stop close the listeners and wait for the receiving goroutines
+func (c *RelayCommand) stop() {
+	for _, closer := range c.closers {
+		_ = closer.Close()
+	}
+	c.mu.Lock()
+	for conn := range c.conns {
+		_ = conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// defaultKey returns the write target of tcp and udp
+func (c *RelayCommand) defaultKey() relayKey {
+	return relayKey{Database: c.cfg.Database, RetentionPolicy: c.cfg.RetentionPolicy, Precision: c.cfg.Precision}
+}
+
+// validLines returns the valid lines of line protocol text, comments and blank lines are removed
+func validLines(raw string, precision string) ([]string, error) {
+	multiplier, err := precisionMultiplier(precision)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	var errs error
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := core.NewLineProtocolParser(line).Parse(multiplier); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid line %q: %w", line, err))
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, errs
+}
+
+// precisionMultiplier returns the nanoseconds of the precision of `/write` api
+func precisionMultiplier(precision string) (int64, error) {
+	switch precision {
+	case "", "n", "ns":
+		return 1, nil
+	case "u", "us":
+		return int64(time.Microsecond), nil
+	case "ms":
+		return int64(time.Millisecond), nil
+	case "s":
+		return int64(time.Second), nil
+	case "m":
+		return int64(time.Minute), nil
+	case "h":
+		return int64(time.Hour), nil
+	}
+	return 0, fmt.Errorf("invalid precision %s", precision)
+}
+
+// httpHandler serves the InfluxDB compatible `/write` and `/ping` api
+func (c *RelayCommand) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/write", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var key = c.defaultKey()
+		query := r.URL.Query()
+		if db := query.Get("db"); db != "" {
+			key.Database = db
+		}
+		if rp := query.Get("rp"); rp != "" {
+			key.RetentionPolicy = rp
+		}
+		if precision := query.Get("precision"); precision != "" {
+			key.Precision = precision
+		}
+		if key.Database == "" {
+			http.Error(w, "database is required", http.StatusBadRequest)
+			return
+		}
+		var body io.Reader = http.MaxBytesReader(w, r.Body, relayMaxBodySize)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(body)
+			if err != nil {
+				relayBodyError(w, err)
+				return
+			}
+			defer gz.Close()
+			body = io.LimitReader(gz, relayMaxBodySize+1)
+		}
+		content, err := io.ReadAll(body)
+		if err != nil {
+			relayBodyError(w, err)
+			return
+		}
+		if len(content) > relayMaxBodySize {
+			http.Error(w, "decompressed request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		lines, err := validLines(string(content), key.Precision)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(lines) != 0 {
+			ack := &relayAck{done: make(chan error, 1)}
+			c.incoming <- &relayBatch{relayKey: key, lines: lines, acks: []*relayAck{ack}}
+			select {
+			case err = <-ack.done:
+			case <-r.Context().Done():
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// relayBodyError reply 413 if the request body exceeds relayMaxBodySize, otherwise 400
+func relayBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+func (c *RelayCommand) serveTCP(listener net.Listener) {
+	defer c.wg.Done()
+	var conns sync.WaitGroup
+	defer conns.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("tcp listener stopped", "reason", err)
+			}
+			return
+		}
+		c.mu.Lock()
+		c.conns[conn] = struct{}{}
+		c.mu.Unlock()
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			defer func() {
+				c.mu.Lock()
+				delete(c.conns, conn)
+				c.mu.Unlock()
+				_ = conn.Close()
+			}()
+			scanner := bufio.NewScanner(conn)
+			scanner.Buffer(make([]byte, 64*1024), relayMaxLineSize)
+			for scanner.Scan() {
+				c.receive(scanner.Text(), "tcp")
+			}
+		}()
+	}
+}
+
+func (c *RelayCommand) serveUDP(conn net.PacketConn) {
+	defer c.wg.Done()
+	var buf = make([]byte, relayMaxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("udp listener stopped", "reason", err)
+			}
+			return
+		}
+		c.receive(string(buf[:n]), "udp")
+	}
+}
+
+// receive the lines of tcp and udp, the invalid lines are dropped
+func (c *RelayCommand) receive(raw string, protocol string) {
+	key := c.defaultKey()
+	if key.Database == "" {
+		slog.Warn("drop lines, --database is required", "protocol", protocol)
+		return
+	}
+	lines, err := validLines(raw, key.Precision)
+	if err != nil {
+		slog.Warn("drop invalid lines", "protocol", protocol, "reason", err)
+	}
+	if len(lines) != 0 {
+		c.incoming <- &relayBatch{relayKey: key, lines: lines}
+	}
+}
+
+// run batches the incoming lines until ctx is done, the batch is flushed when it is full or
+// every --flush-interval
+func (c *RelayCommand) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case batch := <-c.incoming:
+			c.add(ctx, batch)
+		case <-ticker.C:
+			c.flushAll(ctx)
+		case <-ctx.Done():
+			// stop receiving, then flush the remaining lines with a fresh context. The pending
+			// batches are flushed first, the http server waits for the requests of them to shut down.
+			c.flushAll(context.Background())
+			go func() {
+				c.stop()
+				close(c.incoming)
+			}()
+			for batch := range c.incoming {
+				c.add(context.Background(), batch)
+				if len(batch.acks) != 0 { // reply the http request before the server is shut down
+					c.flushAll(context.Background())
+				}
+			}
+			c.flushAll(context.Background())
+			slog.Info("relay stopped")
+			return
+		}
+	}
+}
+
+func (c *RelayCommand) add(ctx context.Context, batch *relayBatch) {
+	pending, ok := c.batches[batch.relayKey]
+	if !ok {
+		pending = &relayBatch{relayKey: batch.relayKey}
+		c.batches[batch.relayKey] = pending
+	}
+	pending.lines = append(pending.lines, batch.lines...)
+	for _, ack := range batch.acks {
+		ack.batches++
+		pending.acks = append(pending.acks, ack)
+	}
+	for len(pending.lines) >= c.cfg.BatchSize {
+		full := &relayBatch{relayKey: pending.relayKey, lines: pending.lines[:c.cfg.BatchSize], acks: pending.acks}
+		pending.lines = append([]string(nil), pending.lines[c.cfg.BatchSize:]...)
+		pending.acks = nil
+		if len(pending.lines) != 0 { // the remaining lines may belong to any of the requests
+			for _, ack := range full.acks {
+				ack.batches++
+				pending.acks = append(pending.acks, ack)
+			}
+		}
+		c.forward(ctx, full)
+	}
+}
+
+func (c *RelayCommand) flushAll(ctx context.Context) {
+	c.drainSpool(ctx)
+	var keys = make([]relayKey, 0, len(c.batches))
+	for key, batch := range c.batches {
+		if len(batch.lines) != 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Database < keys[j].Database })
+	for _, key := range keys {
+		batch := c.batches[key]
+		delete(c.batches, key)
+		c.forward(ctx, batch)
+	}
+}
+
+// forward flush the batch and reply the http requests waiting for it
+func (c *RelayCommand) forward(ctx context.Context, batch *relayBatch) {
+	err := c.flush(ctx, batch)
+	for _, ack := range batch.acks {
+		ack.finish(err)
+	}
+}
+
+// flush forward the batch, it is spooled if the upstream is unavailable. The batch is spooled
+// directly if the spool is not empty to keep the order of data. The error is returned only if
+// the batch is dropped because it cannot be spooled.
+func (c *RelayCommand) flush(ctx context.Context, batch *relayBatch) error {
+	if c.spooled() == 0 {
+		err := c.write(ctx, batch)
+		if err == nil {
+			return nil
+		}
+		if (&importWriteError{err: err}).tooLarge() && len(batch.lines) > 1 {
+			mid := len(batch.lines) / 2
+			slog.Warn("batch is too large, split it", "database", batch.Database, "lines", len(batch.lines))
+			return errors.Join(c.flush(ctx, &relayBatch{relayKey: batch.relayKey, lines: batch.lines[:mid]}),
+				c.flush(ctx, &relayBatch{relayKey: batch.relayKey, lines: batch.lines[mid:]}))
+		}
+		if !relayRetryable(err) {
+			slog.Error("drop batch rejected by upstream", "database", batch.Database, "lines", len(batch.lines), "reason", err)
+			return nil
+		}
+		slog.Warn("forward batch failed", "database", batch.Database, "lines", len(batch.lines), "reason", err)
+	}
+	if err := c.spool(batch); err != nil {
+		slog.Error("drop batch, spool failed", "database", batch.Database, "lines", len(batch.lines), "reason", err)
+		return err
+	}
+	return nil
+}
+
+func (c *RelayCommand) write(ctx context.Context, batch *relayBatch) error {
+	if !c.cfg.ColumnWrite {
+		return c.httpClient.Write(ctx, batch.Database, batch.RetentionPolicy, strings.Join(batch.lines, "\n"), batch.Precision)
+	}
+	multiplier, err := precisionMultiplier(batch.Precision)
+	if err != nil {
+		return err
+	}
+	points, err := core.NewLineProtocolParser(strings.Join(batch.lines, "\n")).Parse(multiplier)
+	if err != nil {
+		return err
+	}
+	if c.encoder == nil {
+		c.encoder = newColumnEncoder(c.cfg.Username, c.cfg.Password)
+	}
+	request, err := c.encoder.encode(batch.Database, batch.RetentionPolicy, points)
+	if err != nil {
+		return err
+	}
+	response, err := c.writeClient.Write(ctx, request)
+	if err != nil {
+		return err
+	}
+	return checkWriteResponse(response)
+}
+
+// spool file is the json of relayKey in the first line followed by the lines
+func (c *RelayCommand) spool(batch *relayBatch) error {
+	if c.cfg.SpoolDir == "" {
+		return errors.New("spool is disabled")
+	}
+	header, err := json.Marshal(batch.relayKey)
+	if err != nil {
+		return err
+	}
+	content := string(header) + "\n" + strings.Join(batch.lines, "\n") + "\n"
+	if c.cfg.SpoolMaxSize > 0 && c.spoolSize+int64(len(content)) > c.cfg.SpoolMaxSize {
+		return errRelaySpoolFull
+	}
+	c.spoolSeq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), c.spoolSeq, relaySpoolSuffix)
+	tmp := filepath.Join(c.cfg.SpoolDir, name+".tmp")
+	if err = os.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, filepath.Join(c.cfg.SpoolDir, name)); err != nil {
+		return err
+	}
+	c.spoolCount++
+	c.spoolSize += int64(len(content))
+	return nil
+}
+
+// loadSpool count the spool files left by the last run
+func (c *RelayCommand) loadSpool() {
+	for _, file := range c.spoolFiles() {
+		if stat, err := os.Stat(file); err == nil {
+			c.spoolCount++
+			c.spoolSize += stat.Size()
+		}
+	}
+}
+
+// spoolFiles returns the spool files in the order of creation
+func (c *RelayCommand) spoolFiles() []string {
+	if c.cfg.SpoolDir == "" {
+		return nil
+	}
+	files, _ := filepath.Glob(filepath.Join(c.cfg.SpoolDir, "*"+relaySpoolSuffix))
+	sort.Strings(files)
+	return files
+}
+
+func (c *RelayCommand) spooled() int {
+	return c.spoolCount
+}
+
+// drainSpool forward the spooled batches in order, stop at the first failure
+func (c *RelayCommand) drainSpool(ctx context.Context) {
+	if c.spoolCount == 0 {
+		return
+	}
+	files := c.spoolFiles()
+	if len(files) == 0 { // the spool files are removed by others
+		c.spoolCount, c.spoolSize = 0, 0
+		return
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			slog.Error("read spool file failed", "file", file, "reason", err)
+			return
+		}
+		header, body, _ := strings.Cut(string(content), "\n")
+		var batch = new(relayBatch)
+		if err = json.Unmarshal([]byte(header), &batch.relayKey); err != nil {
+			slog.Error("drop invalid spool file", "file", file, "reason", err)
+			c.removeSpool(file, len(content))
+			continue
+		}
+		batch.lines = strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+		err = c.write(ctx, batch)
+		if err != nil && relayRetryable(err) {
+			return // upstream is still unavailable
+		}
+		if err != nil {
+			slog.Error("drop spooled batch rejected by upstream", "file", file, "reason", err)
+		} else {
+			slog.Info("forward spooled batch success", "file", file, "lines", len(batch.lines))
+		}
+		c.removeSpool(file, len(content))
+	}
+}
+
+func (c *RelayCommand) removeSpool(file string, size int) {
+	_ = os.Remove(file)
+	c.spoolCount = max(c.spoolCount-1, 0)
+	c.spoolSize = max(c.spoolSize-int64(size), 0)
+}
+
+// relayRetryable returns true if the upstream is unavailable, the batch rejected by upstream is not retryable
+func relayRetryable(err error) bool {
+	return (&importWriteError{err: err}).retryable()
+}
@@ -0,0 +1,235 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func newMockRelayCommand(t *testing.T) (*RelayCommand, *mockHttpClient) {
+	cfg := &RelayConfig{
+		CommandLineConfig: new(core.CommandLineConfig),
+		HTTPListen:        "127.0.0.1:0",
+		TCPListen:         "127.0.0.1:0",
+		UDPListen:         "127.0.0.1:0",
+		BatchSize:         100,
+		FlushInterval:     time.Hour,
+		SpoolDir:          filepath.Join(t.TempDir(), "spool"),
+	}
+	cfg.Database = "db0"
+	client := new(mockHttpClient)
+	return &RelayCommand{cfg: cfg, httpClient: client, writeClient: new(mockWriteClient)}, client
+}
+
+func TestValidLines(t *testing.T) {
+	lines, err := validLines("cpu v=1 1\n\n# comment\nmem v=2 2\n", "s")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cpu v=1 1", "mem v=2 2"}, lines)
+
+	lines, err = validLines("cpu v=1 1\ncpu\n", "")
+	require.Error(t, err)
+	require.Equal(t, []string{"cpu v=1 1"}, lines)
+
+	_, err = validLines("cpu v=1 1", "d")
+	require.Error(t, err)
+}
+
+func TestRelayReceive(t *testing.T) {
+	command, client := newMockRelayCommand(t)
+	require.NoError(t, command.start())
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, _ = gz.Write([]byte("cpu v=1 1\n"))
+	require.NoError(t, gz.Close())
+	request, err := http.NewRequest(http.MethodPost, "http://"+command.addrs["http"].String()+"/write?db=db1&precision=s", &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Encoding", "gzip")
+	// the response is replied after the lines are forwarded
+	var status = make(chan int, 1)
+	go func() {
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			status <- 0
+			return
+		}
+		_ = response.Body.Close()
+		status <- response.StatusCode
+	}()
+
+	response, err := http.Post("http://"+command.addrs["http"].String()+"/write", "text/plain", strings.NewReader("cpu"))
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// the oversize body and the gzip bomb are rejected
+	response, err = http.Post("http://"+command.addrs["http"].String()+"/write?db=db1", "text/plain", bytes.NewReader(make([]byte, relayMaxBodySize+1)))
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	body.Reset()
+	gz = gzip.NewWriter(&body)
+	_, _ = gz.Write(make([]byte, relayMaxBodySize+1))
+	require.NoError(t, gz.Close())
+	request, err = http.NewRequest(http.MethodPost, "http://"+command.addrs["http"].String()+"/write?db=db1", &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Encoding", "gzip")
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+	tcp, err := net.Dial("tcp", command.addrs["tcp"].String())
+	require.NoError(t, err)
+	_, err = tcp.Write([]byte("mem v=2 2\ninvalid\n"))
+	require.NoError(t, err)
+	require.NoError(t, tcp.Close())
+
+	udp, err := net.Dial("udp", command.addrs["udp"].String())
+	require.NoError(t, err)
+	_, err = udp.Write([]byte("disk v=3 3\nnet v=4 4"))
+	require.NoError(t, err)
+	require.NoError(t, udp.Close())
+
+	require.Eventually(t, func() bool { return len(command.incoming) == 3 }, 5*time.Second, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	command.run(ctx)
+	require.Equal(t, http.StatusNoContent, <-status)
+
+	var expect = []string{"mem v=2 2", "disk v=3 3", "net v=4 4", "cpu v=1 1"}
+	require.ElementsMatch(t, expect, client.writes)
+}
+
+func TestRelayWriteSpoolFailed(t *testing.T) {
+	command, client := newMockRelayCommand(t)
+	command.cfg.SpoolDir = ""
+	command.cfg.FlushInterval = 10 * time.Millisecond
+	client.writeErr = errors.New("connection refused")
+	require.NoError(t, command.start())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		command.run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	response, err := http.Post("http://"+command.addrs["http"].String()+"/write?db=db1", "text/plain", strings.NewReader("cpu v=1 1\n"))
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+}
+
+func TestRelayBatchSize(t *testing.T) {
+	command, client := newMockRelayCommand(t)
+	command.cfg.BatchSize = 2
+	command.batches = make(map[relayKey]*relayBatch)
+	var key = command.defaultKey()
+	command.add(context.Background(), &relayBatch{relayKey: key, lines: []string{"a v=1", "b v=1", "c v=1"}})
+	require.Equal(t, []string{"a v=1", "b v=1"}, client.writes)
+	command.flushAll(context.Background())
+	require.Equal(t, []string{"a v=1", "b v=1", "c v=1"}, client.writes)
+
+	// the request is replied after all of its lines are forwarded
+	ack := &relayAck{done: make(chan error, 1)}
+	command.add(context.Background(), &relayBatch{relayKey: key, lines: []string{"d v=1", "e v=1", "f v=1"}, acks: []*relayAck{ack}})
+	require.Empty(t, ack.done)
+	command.flushAll(context.Background())
+	require.NoError(t, <-ack.done)
+}
+
+func TestRelaySpool(t *testing.T) {
+	command, client := newMockRelayCommand(t)
+	require.NoError(t, os.MkdirAll(command.cfg.SpoolDir, 0700))
+	command.batches = make(map[relayKey]*relayBatch)
+	var ctx = context.Background()
+	var key = relayKey{Database: "db0", RetentionPolicy: "rp0", Precision: "s"}
+
+	client.writeErr = errors.New("connection refused")
+	command.flush(ctx, &relayBatch{relayKey: key, lines: []string{"a v=1 1"}})
+	client.writeErr = &core.WriteError{StatusCode: http.StatusServiceUnavailable}
+	command.flush(ctx, &relayBatch{relayKey: key, lines: []string{"b v=1 1"}})
+	require.Equal(t, 2, command.spooled())
+
+	// the upstream is still unavailable, the spool is kept
+	command.flushAll(ctx)
+	require.Equal(t, 2, command.spooled())
+
+	client.writeErr = nil
+	command.add(ctx, &relayBatch{relayKey: key, lines: []string{"c v=1 1"}})
+	command.flushAll(ctx)
+	require.Equal(t, 0, command.spooled())
+	require.Equal(t, []string{"a v=1 1", "b v=1 1", "c v=1 1"}, client.writes)
+
+	// the batch rejected by upstream is dropped
+	client.writeErr = &core.WriteError{StatusCode: http.StatusBadRequest}
+	require.NoError(t, command.flush(ctx, &relayBatch{relayKey: key, lines: []string{"d v=1 1"}}))
+	require.Equal(t, 0, command.spooled())
+}
+
+func TestRelaySpoolMaxSize(t *testing.T) {
+	command, client := newMockRelayCommand(t)
+	command.cfg.SpoolMaxSize = 100
+	require.NoError(t, os.MkdirAll(command.cfg.SpoolDir, 0700))
+	var ctx = context.Background()
+	var key = relayKey{Database: "db0", RetentionPolicy: "rp0", Precision: "s"}
+
+	client.writeErr = errors.New("connection refused")
+	require.NoError(t, command.flush(ctx, &relayBatch{relayKey: key, lines: []string{"a v=1 1"}}))
+	require.ErrorIs(t, command.flush(ctx, &relayBatch{relayKey: key, lines: []string{"b v=1 1"}}), errRelaySpoolFull)
+	require.Equal(t, 1, command.spooled())
+
+	// the spool files left by the last run are counted
+	restarted, client := newMockRelayCommand(t)
+	restarted.cfg.SpoolDir = command.cfg.SpoolDir
+	restarted.cfg.HTTPListen, restarted.cfg.TCPListen = "", ""
+	require.NoError(t, restarted.start())
+	defer restarted.stop()
+	require.Equal(t, 1, restarted.spooled())
+	restarted.batches = make(map[relayKey]*relayBatch)
+	restarted.flushAll(ctx)
+	require.Equal(t, 0, restarted.spooled())
+	require.Equal(t, []string{"a v=1 1"}, client.writes)
+}
+
+func TestRelayColumnWrite(t *testing.T) {
+	command, _ := newMockRelayCommand(t)
+	command.cfg.ColumnWrite = true
+	writeClient := command.writeClient.(*mockWriteClient)
+	var batch = &relayBatch{relayKey: relayKey{Database: "db0", RetentionPolicy: "rp0", Precision: "s"}, lines: []string{"cpu,host=a v=1 1", "mem v=2i 2"}}
+	require.NoError(t, command.write(context.Background(), batch))
+	require.Len(t, writeClient.requests, 1)
+	require.Equal(t, "db0", writeClient.requests[0].Database)
+	require.Equal(t, "rp0", writeClient.requests[0].RetentionPolicy)
+	require.Len(t, writeClient.requests[0].Records, 2)
+}
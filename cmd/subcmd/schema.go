@@ -0,0 +1,332 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+// the field types of `SHOW FIELD KEYS`
+const (
+	fieldTypeFloat    = "float"
+	fieldTypeInteger  = "integer"
+	fieldTypeUnsigned = "unsigned"
+	fieldTypeString   = "string"
+	fieldTypeBoolean  = "boolean"
+)
+
+// maxExactFloatInt the max integer converted to float64 without losing precision
+const maxExactFloatInt = 1 << 53
+
+// fieldType returns the type of the field value
+func fieldType(value any) string {
+	switch value.(type) {
+	case float64, float32:
+		return fieldTypeFloat
+	case int, int8, int16, int32, int64:
+		return fieldTypeInteger
+	case uint, uint8, uint16, uint32, uint64:
+		return fieldTypeUnsigned
+	case bool:
+		return fieldTypeBoolean
+	}
+	return fieldTypeString
+}
+
+// coercible returns true if the type can be converted to the target type, the conversion may
+// still fail for some values such as the float with fraction to integer
+func coercible(from, to string) bool {
+	if from == to || to == fieldTypeString {
+		return true
+	}
+	switch from {
+	case fieldTypeFloat, fieldTypeInteger, fieldTypeUnsigned:
+		return to != fieldTypeBoolean
+	case fieldTypeString:
+		return true
+	}
+	return false
+}
+
+// coerceValue converts the value to the type without losing information
+func coerceValue(value any, typ string) (any, error) {
+	if fieldType(value) == typ {
+		return value, nil
+	}
+	var invalid = fmt.Errorf("value %v can not be converted to %s losslessly", value, typ)
+	switch typ {
+	case fieldTypeString:
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+	case fieldTypeBoolean:
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, nil
+			}
+		}
+		return nil, invalid
+	}
+	// numeric types
+	var f float64
+	var i int64
+	var isInt bool
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int64:
+		i, isInt = v, true
+	case int:
+		i, isInt = int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			if typ == fieldTypeFloat && v <= maxExactFloatInt {
+				return float64(v), nil
+			}
+			return nil, invalid
+		}
+		i, isInt = int64(v), true
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			i, isInt = n, true
+		} else if n, err := strconv.ParseFloat(v, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			f = n
+		} else {
+			return nil, invalid
+		}
+	default:
+		return nil, invalid
+	}
+	if !isInt { // float to integer only if it is integral
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || math.Abs(f) > maxExactFloatInt {
+			if typ == fieldTypeFloat {
+				return f, nil
+			}
+			return nil, invalid
+		}
+		i = int64(f)
+	}
+	switch typ {
+	case fieldTypeFloat:
+		if !isInt {
+			return f, nil
+		}
+		if i > maxExactFloatInt || i < -maxExactFloatInt {
+			return nil, invalid
+		}
+		return float64(i), nil
+	case fieldTypeInteger:
+		return i, nil
+	case fieldTypeUnsigned:
+		if i < 0 {
+			return nil, invalid
+		}
+		return uint64(i), nil
+	}
+	return nil, invalid
+}
+
+// schemaKey identifies the field of measurement
+type schemaKey struct {
+	database    string
+	measurement string
+	field       string
+}
+
+// schemaCollector collects the field types of input by the dry run of import
+type schemaCollector struct {
+	types map[schemaKey]map[string]int // the count of points by field type
+}
+
+func newSchemaCollector() *schemaCollector {
+	return &schemaCollector{types: make(map[schemaKey]map[string]int)}
+}
+
+func (s *schemaCollector) collect(database string, points []*opengemini.Point) {
+	for _, point := range points {
+		for name, value := range point.Fields {
+			key := schemaKey{database: database, measurement: point.Measurement, field: name}
+			types, ok := s.types[key]
+			if !ok {
+				types = make(map[string]int)
+				s.types[key] = types
+			}
+			types[fieldType(value)]++
+		}
+	}
+}
+
+func (s *schemaCollector) databases() []string {
+	var set = make(map[string]struct{})
+	for key := range s.types {
+		set[key.database] = struct{}{}
+	}
+	var databases = make([]string, 0, len(set))
+	for database := range set {
+		databases = append(databases, database)
+	}
+	sort.Strings(databases)
+	return databases
+}
+
+// schemaConflict is the field whose types of input differ from openGemini or each other
+type schemaConflict struct {
+	schemaKey
+	server string         // the type of openGemini, empty if the field does not exist
+	input  map[string]int // the count of points by field type
+}
+
+func (c *schemaConflict) String() string {
+	var types = make([]string, 0, len(c.input))
+	for typ, count := range c.input {
+		types = append(types, fmt.Sprintf("%s(%d)", typ, count))
+	}
+	sort.Strings(types)
+	var server = c.server
+	if server == "" {
+		server = "none"
+	}
+	return fmt.Sprintf("%s.%s field %s: server type %s, input types %s", c.database, c.measurement, c.field, server, strings.Join(types, ", "))
+}
+
+// resolvable returns true if all input types can be coerced to the server type
+func (c *schemaConflict) resolvable() bool {
+	if c.server == "" {
+		return false
+	}
+	for typ := range c.input {
+		if !coercible(typ, c.server) {
+			return false
+		}
+	}
+	return true
+}
+
+// conflicts compare the input types with the server types
+func (s *schemaCollector) conflicts(server map[schemaKey]string) []*schemaConflict {
+	var conflicts []*schemaConflict
+	for key, types := range s.types {
+		serverType, exist := server[key]
+		if (exist && (len(types) > 1 || types[serverType] == 0)) || (!exist && len(types) > 1) {
+			conflicts = append(conflicts, &schemaConflict{schemaKey: key, server: serverType, input: types})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].String() < conflicts[j].String() })
+	return conflicts
+}
+
+// fetchSchema query the field types of the databases by `SHOW FIELD KEYS`, the database not
+// exist is ignored
+func (c *ImportCommand) fetchSchema(ctx context.Context, databases []string) (map[schemaKey]string, error) {
+	var schema = make(map[schemaKey]string)
+	for _, database := range databases {
+		result, err := c.httpClient.Query(ctx, &opengemini.Query{Database: database, Command: "SHOW FIELD KEYS"})
+		if err != nil {
+			return nil, err
+		}
+		if result.Error != "" {
+			return nil, errors.New(result.Error)
+		}
+		for _, res := range result.Results {
+			if res.Error != "" {
+				slog.Warn("show field keys failed", "database", database, "reason", res.Error)
+				continue
+			}
+			for _, series := range res.Series {
+				for _, value := range series.Values {
+					if len(value) < 2 {
+						continue
+					}
+					name, _ := value[0].(string)
+					typ, _ := value[1].(string)
+					schema[schemaKey{database: database, measurement: series.Name, field: name}] = typ
+				}
+			}
+		}
+	}
+	return schema, nil
+}
+
+// preflightSchema dry run the import to collect the field types, compare them with openGemini
+// and report the conflicts before writing. The conflicts resolvable by --coerce are allowed.
+func (c *ImportCommand) preflightSchema(ctx context.Context) error {
+	var staticTags = c.fsm.staticTags
+	c.preflight = newSchemaCollector()
+	err := c.importFile(ctx)
+	collector := c.preflight
+	c.preflight = nil
+	c.fsm = &ImportFileFSM{staticTags: staticTags}
+	c.stats = importStats{}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Code == ExitCodeFailure {
+		return err
+	}
+
+	server, err := c.fetchSchema(ctx, collector.databases())
+	if err != nil {
+		slog.Error("fetch schema failed", "reason", err)
+		return &ExitError{Code: ExitCodeConnection, Err: err}
+	}
+	var unresolved int
+	for _, conflict := range collector.conflicts(server) {
+		if c.cfg.Coerce && conflict.resolvable() {
+			slog.Warn("field type conflict, the values are coerced to the server type", "conflict", conflict.String())
+			continue
+		}
+		slog.Error("field type conflict", "conflict", conflict.String())
+		unresolved++
+	}
+	if unresolved > 0 {
+		return &ExitError{Code: ExitCodeSchemaConflict, Err: fmt.Errorf("%d field type conflicts found", unresolved)}
+	}
+	if c.cfg.Coerce {
+		c.schema = server
+	}
+	slog.Info("schema preflight passed", "fields", len(collector.types))
+	return nil
+}
+
+// coercePoint converts the field values of the point to the server types by --coerce
+func (c *ImportCommand) coercePoint(point *opengemini.Point) error {
+	if c.schema == nil {
+		return nil
+	}
+	for name, value := range point.Fields {
+		typ, ok := c.schema[schemaKey{database: c.fsm.database, measurement: point.Measurement, field: name}]
+		if !ok {
+			continue
+		}
+		coerced, err := coerceValue(value, typ)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %w", name, point.Measurement, err)
+		}
+		point.Fields[name] = coerced
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceValue(t *testing.T) {
+	type testCase struct {
+		value  any
+		typ    string
+		expect any
+	}
+	testCases := []testCase{
+		{float64(3), fieldTypeInteger, int64(3)},
+		{float64(3), fieldTypeUnsigned, uint64(3)},
+		{int64(3), fieldTypeFloat, float64(3)},
+		{uint64(3), fieldTypeInteger, int64(3)},
+		{"3", fieldTypeInteger, int64(3)},
+		{"3.5", fieldTypeFloat, 3.5},
+		{"true", fieldTypeBoolean, true},
+		{int64(3), fieldTypeString, "3"},
+		{0.5, fieldTypeString, "0.5"},
+		{true, fieldTypeString, "true"},
+	}
+	for _, tcase := range testCases {
+		act, err := coerceValue(tcase.value, tcase.typ)
+		require.NoError(t, err)
+		require.Equal(t, tcase.expect, act)
+	}
+
+	for _, tcase := range []testCase{
+		{3.5, fieldTypeInteger, nil},
+		{int64(-1), fieldTypeUnsigned, nil},
+		{int64(1<<53 + 1), fieldTypeFloat, nil},
+		{"abc", fieldTypeFloat, nil},
+		{"yes", fieldTypeBoolean, nil},
+		{true, fieldTypeInteger, nil},
+	} {
+		_, err := coerceValue(tcase.value, tcase.typ)
+		require.Error(t, err, tcase.value)
+	}
+}
+
+func TestSchemaConflicts(t *testing.T) {
+	collector := newSchemaCollector()
+	collector.collect("db0", []*opengemini.Point{
+		{Measurement: "cpu", Fields: map[string]any{"usage": 0.5, "count": int64(1), "host": "a"}},
+		{Measurement: "cpu", Fields: map[string]any{"usage": int64(1), "count": int64(2)}},
+		{Measurement: "mem", Fields: map[string]any{"used": "10"}},
+	})
+	conflicts := collector.conflicts(map[schemaKey]string{
+		{database: "db0", measurement: "cpu", field: "count"}: fieldTypeInteger,
+		{database: "db0", measurement: "mem", field: "used"}:  fieldTypeFloat,
+	})
+	require.Len(t, conflicts, 2)
+	require.Equal(t, "db0.cpu field usage: server type none, input types float(1), integer(1)", conflicts[0].String())
+	require.False(t, conflicts[0].resolvable())
+	require.Equal(t, "db0.mem field used: server type float, input types string(1)", conflicts[1].String())
+	require.True(t, conflicts[1].resolvable())
+}
+
+func TestImportPreflight(t *testing.T) {
+	var responder = func(command string) *opengemini.QueryResult {
+		if !strings.HasPrefix(command, "SHOW FIELD KEYS") {
+			return &opengemini.QueryResult{}
+		}
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Series: []*opengemini.Series{
+			{Name: "a.b", Columns: []string{"fieldKey", "fieldType"}, Values: opengemini.SeriesValues{{"value", "integer"}}},
+		}}}}
+	}
+	command, client := newMockImportCommand(t, importFormatGraphite, "a.b 1 1\na.c 2.5 1\n")
+	command.cfg.Preflight = true
+	client.responder = responder
+	requireExitCode(t, ExitCodeSchemaConflict, command.process())
+	require.Equal(t, []string{"SHOW FIELD KEYS"}, client.queries)
+	require.Empty(t, client.writes)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\na.c 2.5 1\n")
+	command.cfg.Coerce = true
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"SHOW FIELD KEYS", "SHOW DATABASES", `CREATE DATABASE "db0"`}, client.queries)
+	require.Equal(t, []string{"a.b value=1i 1000000000", "a.c value=2.5 1000000000", ""}, client.writes)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, "a.b 1.5 1\n")
+	command.cfg.Coerce = true
+	client.responder = responder
+	requireExitCode(t, ExitCodeParseError, command.process())
+	require.Empty(t, client.writes)
+}
+
+func TestImportPreflightLineProtocol(t *testing.T) {
+	var responder = func(command string) *opengemini.QueryResult {
+		if !strings.HasPrefix(command, "SHOW FIELD KEYS") {
+			return &opengemini.QueryResult{}
+		}
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Series: []*opengemini.Series{
+			{Name: "cpu", Columns: []string{"fieldKey", "fieldType"}, Values: opengemini.SeriesValues{
+				{"usage", "float"}, {"count", "integer"}, {"note", "string"}, {"ok", "boolean"},
+			}},
+		}}}}
+	}
+	const content = "# DML\n# CONTEXT-DATABASE: db0\ncpu,host=a usage=1.5,count=3i,note=\"n\",ok=t 1\ncpu,host=b usage=2,count=4i,idle=5 2\n"
+	command, client := newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Preflight = true
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, []string{
+		`cpu,host=a usage=1.5,count=3i,note="n",ok=t 1`,
+		`cpu,host=b usage=2,count=4i,idle=5 2`,
+	}, client.writes)
+
+	command, client = newMockImportCommand(t, importFormatLineProtocol, "# DML\n# CONTEXT-DATABASE: db0\ncpu count=1.5 1\n")
+	command.cfg.Preflight = true
+	client.responder = responder
+	requireExitCode(t, ExitCodeSchemaConflict, command.process())
+	require.Empty(t, client.writes)
+
+	// the float is coerced to the server integer type, the new field keeps its type
+	command, client = newMockImportCommand(t, importFormatLineProtocol, "# DML\n# CONTEXT-DATABASE: db0\ncpu count=3,usage=1i,idle=5 1\n")
+	command.cfg.Coerce = true
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, []string{"cpu count=3i,idle=5,usage=1 1", ""}, client.writes)
+
+	// the followed file cannot be checked before the import
+	command, client = newMockImportCommand(t, importFormatLineProtocol, "# DML\n# CONTEXT-DATABASE: db0\ncpu count=3 1\n")
+	command.cfg.Coerce = true
+	command.cfg.Follow = true
+	requireExitCode(t, ExitCodeFailure, command.process())
+	require.Empty(t, client.queries)
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+const (
+	retentionCheckOff  = "off"
+	retentionCheckWarn = "warn"
+	retentionCheckSkip = "skip"
+)
+
+// timeNow returns the current time, it is replaced by tests
+var timeNow = time.Now
+
+// timeRange is the time range [start, end) in nanoseconds, nil means unlimited
+type timeRange struct {
+	start int64
+	end   int64
+}
+
+// parseTimeRange parse the RFC3339 --start and --end, the empty bound is unlimited
+func parseTimeRange(start, end string) (*timeRange, error) {
+	if start == "" && end == "" {
+		return nil, nil
+	}
+	var tr = &timeRange{start: math.MinInt64, end: math.MaxInt64}
+	for _, bound := range []struct {
+		value string
+		ns    *int64
+	}{{start, &tr.start}, {end, &tr.end}} {
+		if bound.value == "" {
+			continue
+		}
+		tt, err := time.Parse(time.RFC3339Nano, bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %s: %w", bound.value, err)
+		}
+		*bound.ns = tt.UnixNano()
+	}
+	if tr.start >= tr.end {
+		return nil, fmt.Errorf("--start %s must be before --end %s", start, end)
+	}
+	return tr, nil
+}
+
+func (tr *timeRange) contains(ns int64) bool {
+	return tr == nil || (ns >= tr.start && ns < tr.end)
+}
+
+// retentionPolicy is the durations of retention policy, zero duration means infinite
+type retentionPolicy struct {
+	name               string
+	duration           time.Duration
+	shardGroupDuration time.Duration
+	replicaN           int
+	isDefault          bool
+}
+
+// expired returns true if the shard group of the point is out of the retention policy duration,
+// such point is dropped by openGemini.
+func (rp *retentionPolicy) expired(ns int64, now time.Time) bool {
+	if rp == nil || rp.duration <= 0 {
+		return false
+	}
+	var groupEnd = ns
+	if sg := int64(rp.shardGroupDuration); sg > 0 {
+		groupEnd = ns - ns%sg + sg
+		if ns < 0 && ns%sg != 0 {
+			groupEnd -= sg
+		}
+	}
+	return groupEnd <= now.Add(-rp.duration).UnixNano()
+}
+
+// fetchRetentionPolicy query the retention policy by `SHOW RETENTION POLICIES`, the default one
+// is returned if name is empty, nil if it is not found
+func (c *ImportCommand) fetchRetentionPolicy(ctx context.Context, database, name string) (*retentionPolicy, error) {
+	result, err := c.httpClient.Query(ctx, &opengemini.Query{Database: database, Command: "SHOW RETENTION POLICIES ON " + quoteIdentifier(database)})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	for _, res := range result.Results {
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		for _, series := range res.Series {
+			var columns = make(map[string]int)
+			for idx, column := range series.Columns {
+				columns[column] = idx
+			}
+			for _, value := range series.Values {
+				var cell = func(column string) any {
+					if idx, ok := columns[column]; ok && idx < len(value) {
+						return value[idx]
+					}
+					return nil
+				}
+				rpName, _ := cell("name").(string)
+				isDefault, _ := cell("default").(bool)
+				if rpName != name && !(name == "" && isDefault) {
+					continue
+				}
+				var rp = &retentionPolicy{name: rpName, replicaN: toInt(cell("replicaN")), isDefault: isDefault}
+				if s, ok := cell("duration").(string); ok {
+					if rp.duration, err = time.ParseDuration(s); err != nil {
+						return nil, fmt.Errorf("invalid duration of retention policy %s: %w", rpName, err)
+					}
+				}
+				if s, ok := cell("shardGroupDuration").(string); ok {
+					if rp.shardGroupDuration, err = time.ParseDuration(s); err != nil {
+						return nil, fmt.Errorf("invalid shard group duration of retention policy %s: %w", rpName, err)
+					}
+				}
+				return rp, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// retentionPolicyOf returns the cached retention policy of the current database and retention policy
+func (c *ImportCommand) retentionPolicyOf(ctx context.Context) *retentionPolicy {
+	var key = c.fsm.database + "." + c.fsm.retentionPolicy
+	if rp, ok := c.retentionPolicies[key]; ok {
+		return rp
+	}
+	if c.retentionPolicies == nil {
+		c.retentionPolicies = make(map[string]*retentionPolicy)
+	}
+	rp, err := c.fetchRetentionPolicy(ctx, c.fsm.database, c.fsm.retentionPolicy)
+	if err != nil {
+		slog.Warn("fetch retention policy failed, skip the retention check", "database", c.fsm.database, "retention_policy", c.fsm.retentionPolicy, "reason", err)
+	}
+	c.retentionPolicies[key] = rp
+	return rp
+}
+
+// filterTime returns false if the point of timestamp should not be written by --start, --end and
+// --retention-check. The point without timestamp is always written.
+func (c *ImportCommand) filterTime(ctx context.Context, ns int64) bool {
+	if ns == core.NoTimestamp {
+		return true
+	}
+	if !c.timeRange.contains(ns) {
+		c.stats.filtered++
+		return false
+	}
+	if c.cfg.RetentionCheck == "" || c.cfg.RetentionCheck == retentionCheckOff || c.fsm.database == "" {
+		return true
+	}
+	rp := c.retentionPolicyOf(ctx)
+	if !rp.expired(ns, timeNow()) {
+		return true
+	}
+	if c.stats.expired == 0 {
+		slog.Warn("point is out of the retention policy duration, it will be dropped by openGemini", "database", c.fsm.database,
+			"retention_policy", rp.name, "duration", rp.duration, "time", time.Unix(0, ns).UTC())
+	}
+	c.stats.expired++
+	return c.cfg.RetentionCheck != retentionCheckSkip
+}
+
+// filterLine returns true if the line protocol should be parsed to filter by time
+func (c *ImportCommand) filterLine() bool {
+	return c.timeRange != nil || (c.cfg.RetentionCheck != "" && c.cfg.RetentionCheck != retentionCheckOff)
+}
+
+func validateRetentionCheck(check string) error {
+	switch check {
+	case "", retentionCheckOff, retentionCheckWarn, retentionCheckSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid --retention-check %s, only support off, warn, skip", check)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openGemini/openGemini-cli/core"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	tr, err := parseTimeRange("", "")
+	require.NoError(t, err)
+	require.Nil(t, tr)
+	require.True(t, tr.contains(1))
+
+	tr, err = parseTimeRange("1970-01-01T00:00:02Z", "")
+	require.NoError(t, err)
+	require.False(t, tr.contains(1e9))
+	require.True(t, tr.contains(2e9))
+
+	tr, err = parseTimeRange("", "1970-01-01T00:00:02Z")
+	require.NoError(t, err)
+	require.True(t, tr.contains(1e9))
+	require.False(t, tr.contains(2e9))
+
+	_, err = parseTimeRange("1970-01-01T00:00:02Z", "1970-01-01T00:00:01Z")
+	require.Error(t, err)
+	_, err = parseTimeRange("yesterday", "")
+	require.Error(t, err)
+}
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Unix(100*3600, 0)
+	rp := &retentionPolicy{duration: 10 * time.Hour, shardGroupDuration: time.Hour}
+	require.False(t, rp.expired(int64(95*time.Hour), now))
+	// the shard group [89h, 90h) ends at the retention boundary
+	require.True(t, rp.expired(int64(89*time.Hour+time.Minute), now))
+	require.False(t, rp.expired(int64(90*time.Hour+time.Minute), now))
+
+	require.False(t, (&retentionPolicy{}).expired(1, now))
+	require.False(t, (*retentionPolicy)(nil).expired(1, now))
+}
+
+func TestImportTimeFilter(t *testing.T) {
+	content := `a.b 1 1
+a.b 2 2
+a.b 3 3
+`
+	command, client := newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.Start = "1970-01-01T00:00:02Z"
+	command.cfg.End = "1970-01-01T00:00:03Z"
+	require.NoError(t, command.process())
+	require.Equal(t, 2, command.stats.filtered)
+	require.Equal(t, 1, command.stats.written)
+	require.Equal(t, "a.b value=2 2000000000", client.writes[0])
+
+	content = `# DML
+# CONTEXT-DATABASE: db0
+cpu v=0 0
+cpu v=1 1
+cpu v=2 2
+`
+	command, client = newMockImportCommand(t, importFormatLineProtocol, content)
+	command.cfg.Start = "1970-01-01T00:00:00.000000002Z"
+	require.NoError(t, command.process())
+	require.Equal(t, 2, command.stats.filtered) // the epoch is filtered as well
+	require.Equal(t, "cpu v=2 2", strings.TrimSpace(strings.Join(client.writes, "\n")))
+	require.True(t, command.filterTime(context.Background(), core.NoTimestamp))
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, "a.b 1 1\n")
+	command.cfg.Start = "1970-01-01T00:00:02Z"
+	command.cfg.End = "1970-01-01T00:00:01Z"
+	requireExitCode(t, ExitCodeFailure, command.process())
+}
+
+func TestImportRetentionCheck(t *testing.T) {
+	now := timeNow
+	timeNow = func() time.Time { return time.Unix(100*3600, 0) }
+	defer func() { timeNow = now }()
+
+	content := `a.b 1 360000
+a.b 2 3600
+`
+	responder := func(command string) *opengemini.QueryResult {
+		if !strings.HasPrefix(command, "SHOW RETENTION POLICIES") {
+			return &opengemini.QueryResult{}
+		}
+		return &opengemini.QueryResult{Results: []*opengemini.SeriesResult{{Series: []*opengemini.Series{{
+			Columns: []string{"name", "duration", "shardGroupDuration", "default"},
+			Values: []opengemini.SeriesValue{
+				{"autogen", "10h0m0s", "1h0m0s", true},
+				{"forever", "0s", "168h0m0s", false},
+			},
+		}}}}}
+	}
+
+	command, client := newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.RetentionCheck = retentionCheckWarn
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, 1, command.stats.expired)
+	require.Equal(t, 2, command.stats.written)
+
+	command, client = newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.RetentionCheck = retentionCheckSkip
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, 1, command.stats.expired)
+	require.Equal(t, 1, command.stats.written)
+	require.Equal(t, 1, strings.Count(strings.Join(client.queries, "\n"), "SHOW RETENTION POLICIES"))
+
+	command, client = newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.RetentionPolicy = "forever"
+	command.cfg.RetentionCheck = retentionCheckSkip
+	client.responder = responder
+	require.NoError(t, command.process())
+	require.Equal(t, 0, command.stats.expired)
+	require.Equal(t, 2, command.stats.written)
+
+	command, _ = newMockImportCommand(t, importFormatGraphite, content)
+	command.cfg.RetentionCheck = "drop"
+	requireExitCode(t, ExitCodeFailure, command.process())
+}
@@ -132,6 +132,10 @@ func (cl *CommandLine) executeOnLocal(stmt geminiql.Statement) error {
 		return errors.New("not implemented")
 	case *geminiql.VerticalStatement:
 		return cl.executeVertical(stmt)
+	case *geminiql.CompressStatement:
+		return cl.executeCompress(stmt)
+	case *geminiql.ConnectStatement:
+		return cl.executeConnect(stmt)
 	default:
 		return fmt.Errorf("unsupport stmt %s", stmt)
 	}
@@ -305,8 +309,11 @@ func (cl *CommandLine) executeHelp(stmt *geminiql.HelpStatement) error {
   prompt                     enable command line reminder and suggestion, type to turn on or off
   vertical                   print query output rows vertically, type to turn on or off
   auth                       prompt for username and password
+  auth token                 prompt for bearer token
   use <db>[.rp]              set current database and optional retention policy
+  \connect <dsn>             switch to the server of connection string such as opengemini://host:8086/db0
   precision <format>         specifies the format of the timestamp: rfc3339, h, m, s, ms, u or ns
+  compress <algorithm>       compress the written data and query results: none, gzip or zstd
   show cluster               show cluster node status information
   show users                 show all existing users and their permission status
   show databases             show a list of all databases on the cluster
@@ -322,6 +329,21 @@ func (cl *CommandLine) executeHelp(stmt *geminiql.HelpStatement) error {
 }
 
 func (cl *CommandLine) executeAuth(stmt *geminiql.AuthStatement) error {
+	switch strings.ToLower(stmt.Method) {
+	case "":
+	case "token":
+		fmt.Printf("token: ")
+		token, _ := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Printf("\n")
+		cl.Token = strings.TrimSpace(string(token))
+		if cl.Token == "" {
+			return errors.New("token is empty")
+		}
+		cl.httpClient.SetToken(StaticToken(cl.Token))
+		return nil
+	default:
+		return fmt.Errorf("unknown auth method %q, use auth or auth token", stmt.Method)
+	}
 	fmt.Printf("username: ")
 	_, _ = fmt.Scanf("%s\n", &cl.Username)
 	fmt.Printf("password: ")
@@ -329,6 +351,7 @@ func (cl *CommandLine) executeAuth(stmt *geminiql.AuthStatement) error {
 	fmt.Printf("\n")
 	cl.Password = string(password)
 	cl.httpClient.SetAuth(cl.Username, cl.Password)
+	cl.httpClient.SetToken(nil)
 	return nil
 }
 
@@ -381,6 +404,42 @@ func (cl *CommandLine) executeVertical(stmt *geminiql.VerticalStatement) error {
 	return nil
 }
 
+func (cl *CommandLine) executeCompress(stmt *geminiql.CompressStatement) error {
+	compression, err := ParseCompression(stmt.Compression)
+	if err != nil {
+		return err
+	}
+	if err = cl.httpClient.SetCompression(compression); err != nil {
+		return err
+	}
+	cl.Compression = compression
+	fmt.Printf("Compression is %s\n", compression)
+	return nil
+}
+
+func (cl *CommandLine) executeConnect(stmt *geminiql.ConnectStatement) error {
+	// the credentials and database of the current server are not carried to the new one
+	var cfg = *cl.CommandLineConfig
+	cfg.UnixSocket, cfg.Username, cfg.Password = "", "", ""
+	cfg.Token, cfg.TokenFile, cfg.TokenCommand = "", "", ""
+	cfg.Database, cfg.RetentionPolicy = "", ""
+	if err := ParseDSN(stmt.DSN, &cfg); err != nil {
+		return err
+	}
+	httpClient, err := NewHttpClient(&cfg)
+	if err != nil {
+		return err
+	}
+	if err = httpClient.Ping(); err != nil {
+		return fmt.Errorf("connect to %s failed: %w", cfg.Host, err)
+	}
+	httpClient.SetDebug(cl.debug)
+	cl.httpClient = httpClient
+	*cl.CommandLineConfig = cfg
+	fmt.Printf("Connected to %s, database: %s\n", cfg.Host, cfg.Database)
+	return nil
+}
+
 func maxColumnNameWidth(names []string) int {
 	var maxWidth int
 	for _, name := range names {
@@ -0,0 +1,103 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compression algorithms of the write body and query response
+const (
+	CompressNone = "none"
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+var gzipWriterPool = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// zstd encoder and decoder are safe for concurrent EncodeAll and DecodeAll
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ParseCompression normalize the compression algorithm, empty is none
+func ParseCompression(compression string) (string, error) {
+	switch compression = strings.ToLower(compression); compression {
+	case "", CompressNone:
+		return CompressNone, nil
+	case CompressGzip, CompressZstd:
+		return compression, nil
+	}
+	return "", fmt.Errorf("unknown compression %q, compression must be none, gzip or zstd", compression)
+}
+
+// compressBody compress the raw body by the algorithm
+func compressBody(compression string, raw []byte) ([]byte, error) {
+	switch compression {
+	case CompressGzip:
+		var buf bytes.Buffer
+		writer := gzipWriterPool.Get().(*gzip.Writer)
+		defer gzipWriterPool.Put(writer)
+		writer.Reset(&buf)
+		if _, err := writer.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressZstd:
+		return zstdEncoder.EncodeAll(raw, make([]byte, 0, len(raw)/4)), nil
+	}
+	return raw, nil
+}
+
+// acceptEncoding returns the Accept-Encoding header value of the algorithm, the server may not support zstd
+// so gzip is always acceptable
+func acceptEncoding(compression string) string {
+	if compression == CompressZstd {
+		return "zstd, gzip"
+	}
+	return CompressGzip
+}
+
+// decompressBody read the response body by its Content-Encoding
+func decompressBody(response *http.Response) ([]byte, error) {
+	switch strings.ToLower(response.Header.Get("Content-Encoding")) {
+	case CompressGzip:
+		reader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	case CompressZstd:
+		data, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompression(t *testing.T) {
+	for input, expect := range map[string]string{"": CompressNone, "none": CompressNone, "GZIP": CompressGzip, "zstd": CompressZstd} {
+		compression, err := ParseCompression(input)
+		require.NoError(t, err)
+		require.Equal(t, expect, compression)
+	}
+	_, err := ParseCompression("lz4")
+	require.Error(t, err)
+}
+
+func TestHttpClientCompression(t *testing.T) {
+	const raw = "cpu,host=h1 usage=1 1\ncpu,host=h2 usage=2 2"
+	const result = `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],"values":[["db0"]]}]}]}`
+
+	var written, encoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/write":
+			body, err := decompressBody(&http.Response{Header: r.Header, Body: r.Body})
+			require.NoError(t, err)
+			written, encoding = string(body), r.Header.Get("Content-Encoding")
+			w.WriteHeader(http.StatusNoContent)
+		case "/query":
+			// reply by the preferred accepted encoding
+			accepted := strings.Split(r.Header.Get("Accept-Encoding"), ",")[0]
+			body, err := compressBody(strings.TrimSpace(accepted), []byte(result))
+			require.NoError(t, err)
+			if accepted != "" {
+				w.Header().Set("Content-Encoding", accepted)
+			}
+			_, _ = w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	for _, compression := range []string{CompressNone, CompressGzip, CompressZstd} {
+		t.Run(compression, func(t *testing.T) {
+			client := &HttpClientCreator{endpoints: newEndpointPool(BalanceRoundRobin, server.URL), client: server.Client()}
+			require.NoError(t, client.SetCompression(compression))
+
+			require.NoError(t, client.Write(context.Background(), "db0", "", raw, "ns"))
+			require.Equal(t, raw, written)
+			if compression == CompressNone {
+				require.Empty(t, encoding)
+			} else {
+				require.Equal(t, compression, encoding)
+			}
+
+			response, err := client.Query(context.Background(), &opengemini.Query{Command: "SHOW DATABASES"})
+			require.NoError(t, err)
+			require.Equal(t, "db0", response.Results[0].Series[0].Values[0][0])
+		})
+	}
+}
+
+func TestDecompressBodyIdentity(t *testing.T) {
+	data, err := decompressBody(&http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader("raw"))})
+	require.NoError(t, err)
+	require.Equal(t, "raw", string(data))
+}
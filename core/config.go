@@ -15,11 +15,15 @@
 package core
 
 type CommandLineConfig struct {
+	DSN              string
 	Host             string
 	Port             int
 	UnixSocket       string
 	Username         string
 	Password         string
+	Token            string
+	TokenFile        string
+	TokenCommand     string
 	Database         string
 	RetentionPolicy  string
 	Measurement      string
@@ -33,4 +37,6 @@ type CommandLineConfig struct {
 	Precision        string
 	TimeMultiplier   int64
 	DisplayVertical  bool
+	Compression      string
+	Balance          string
 }
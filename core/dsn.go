@@ -0,0 +1,142 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemes of the connection string
+const (
+	dsnScheme      = "opengemini"
+	dsnSchemeHttp  = "opengemini+http"
+	dsnSchemeHttps = "opengemini+https"
+)
+
+// ParseDSN apply the connection string such as
+// 'opengemini+https://user:password@[::1]:8086,host2/db0?rp=autogen&precision=ms&ca=/path&insecure=true'
+// to the config, only the parts present in the connection string are changed
+func ParseDSN(dsn string, cfg *CommandLineConfig) error {
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return fmt.Errorf("invalid connection string %q, expect %s://host:port/database", dsn, dsnScheme)
+	}
+	switch strings.ToLower(scheme) {
+	case dsnScheme, dsnSchemeHttp:
+		cfg.EnableTls = false
+	case dsnSchemeHttps:
+		cfg.EnableTls = true
+	default:
+		return fmt.Errorf("unknown scheme %q of connection string, support %s, %s and %s", scheme, dsnScheme, dsnSchemeHttp, dsnSchemeHttps)
+	}
+
+	// the authority is split by hand, net/url rejects the comma separated hosts
+	var authority = rest
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		authority, rest = rest[:i], rest[i:]
+	} else {
+		rest = ""
+	}
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		username, password, hasPassword := strings.Cut(authority[:at], ":")
+		var err error
+		if cfg.Username, err = url.PathUnescape(username); err != nil {
+			return fmt.Errorf("invalid username of connection string: %w", err)
+		}
+		if hasPassword {
+			if cfg.Password, err = url.PathUnescape(password); err != nil {
+				return fmt.Errorf("invalid password of connection string: %w", err)
+			}
+		}
+		authority = authority[at+1:]
+	}
+	if authority != "" {
+		if _, err := ParseEndpoints(authority, cfg.Port); err != nil {
+			return err
+		}
+		cfg.Host = authority
+	}
+
+	path, query, _ := strings.Cut(rest, "?")
+	if path = strings.Trim(path, "/"); path != "" {
+		database, err := url.PathUnescape(path)
+		if err != nil || strings.Contains(database, "/") {
+			return fmt.Errorf("invalid database %q of connection string", path)
+		}
+		cfg.Database = database
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("invalid options of connection string: %w", err)
+	}
+	var keys = make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err = applyDSNOption(cfg, key, values.Get(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyDSNOption(cfg *CommandLineConfig, key, value string) error {
+	var err error
+	switch key {
+	case "rp":
+		cfg.RetentionPolicy = value
+	case "precision":
+		cfg.Precision = value
+	case "timeout":
+		// milliseconds like --timeout or duration such as 10s
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(value); err == nil {
+			cfg.Timeout = int(timeout.Milliseconds())
+		} else {
+			cfg.Timeout, err = strconv.Atoi(value)
+		}
+	case "ca":
+		cfg.CACert = value
+	case "cert":
+		cfg.Cert = value
+	case "cert-key":
+		cfg.CertKey = value
+	case "insecure":
+		cfg.InsecureTls, err = strconv.ParseBool(value)
+	case "insecure-hostname":
+		cfg.InsecureHostname, err = strconv.ParseBool(value)
+	case "token":
+		cfg.Token = value
+	case "token-file":
+		cfg.TokenFile = value
+	case "compress":
+		cfg.Compression, err = ParseCompression(value)
+	case "balance":
+		cfg.Balance, err = ValidateBalance(value)
+	default:
+		return fmt.Errorf("unknown option %q of connection string", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid option %s=%s of connection string: %w", key, value, err)
+	}
+	return nil
+}
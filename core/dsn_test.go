@@ -0,0 +1,66 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSN(t *testing.T) {
+	cfg := &CommandLineConfig{Host: "localhost", Port: 8086, Timeout: 1000, Precision: "ns"}
+	err := ParseDSN("opengemini+https://user:p%40ss@[::1]:8087/db0?rp=autogen&precision=ms&ca=/path/ca.pem&insecure=true&timeout=10s", cfg)
+	require.NoError(t, err)
+	require.Equal(t, &CommandLineConfig{
+		Host:            "[::1]:8087",
+		Port:            8086,
+		Username:        "user",
+		Password:        "p@ss",
+		Database:        "db0",
+		RetentionPolicy: "autogen",
+		Timeout:         10000,
+		EnableTls:       true,
+		InsecureTls:     true,
+		CACert:          "/path/ca.pem",
+		Precision:       "ms",
+	}, cfg)
+	endpoints, err := ParseEndpoints(cfg.Host, cfg.Port)
+	require.NoError(t, err)
+	require.Equal(t, []string{"[::1]:8087"}, endpoints)
+
+	// the parts not in the connection string are kept
+	cfg = &CommandLineConfig{Host: "localhost", Port: 8086, Database: "db1", Username: "root", Password: "secret"}
+	require.NoError(t, ParseDSN("opengemini://a:8086,b?balance=least-latency&compress=gzip&timeout=500", cfg))
+	require.Equal(t, "a:8086,b", cfg.Host)
+	require.Equal(t, "db1", cfg.Database)
+	require.Equal(t, "root", cfg.Username)
+	require.Equal(t, BalanceLeastLatency, cfg.Balance)
+	require.Equal(t, CompressGzip, cfg.Compression)
+	require.Equal(t, 500, cfg.Timeout)
+	require.False(t, cfg.EnableTls)
+
+	for _, dsn := range []string{
+		"localhost:8086",
+		"mysql://localhost:8086",
+		"opengemini://localhost:port",
+		"opengemini://localhost/db0/rp",
+		"opengemini://localhost?unknown=1",
+		"opengemini://localhost?insecure=maybe",
+		"opengemini://localhost?compress=lz4",
+	} {
+		require.Error(t, ParseDSN(dsn, &CommandLineConfig{Port: 8086}), dsn)
+	}
+}
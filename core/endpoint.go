@@ -0,0 +1,180 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// selection policies of the endpoints
+const (
+	BalanceRoundRobin   = "round-robin"
+	BalanceLeastLatency = "least-latency"
+)
+
+const (
+	// endpointRetryInterval is the time an endpoint is skipped after the connection error, it is probed by ping
+	// before serving requests again
+	endpointRetryInterval = 10 * time.Second
+	// endpointProbeTimeout limit the ping of the endpoint to recover
+	endpointProbeTimeout = 3 * time.Second
+	// latencyDecay is the weight of the history in the moving average of latency
+	latencyDecay = 0.8
+)
+
+// ParseEndpoints split the comma separated --host such as 'a:8086,b,[::1]:8086' into 'host:port' endpoints, the
+// host without port uses the default port
+func ParseEndpoints(hosts string, defaultPort int) ([]string, error) {
+	var endpoints []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		port := strconv.Itoa(defaultPort)
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			if _, err = strconv.ParseUint(p, 10, 16); err != nil {
+				return nil, fmt.Errorf("invalid port of host %q", host)
+			}
+			host, port = h, p
+		} else if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			// [ipv6] without port, the bare ipv6 address is used as it is
+			host = host[1 : len(host)-1]
+		}
+		endpoints = append(endpoints, net.JoinHostPort(host, port))
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("host is required")
+	}
+	return endpoints, nil
+}
+
+// ValidateBalance check the selection policy, empty is round-robin
+func ValidateBalance(balance string) (string, error) {
+	switch balance {
+	case "", BalanceRoundRobin:
+		return BalanceRoundRobin, nil
+	case BalanceLeastLatency:
+		return balance, nil
+	}
+	return "", fmt.Errorf("unknown balance %q, balance must be round-robin or least-latency", balance)
+}
+
+type endpoint struct {
+	url       string // scheme://host:port
+	latency   time.Duration
+	downUntil time.Time
+	down      bool
+}
+
+// endpointPool select the endpoint of each request, the endpoint with connection error is skipped until it is
+// probed again
+type endpointPool struct {
+	balance string
+	now     func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+}
+
+func newEndpointPool(balance string, urls ...string) *endpointPool {
+	pool := &endpointPool{balance: balance, now: time.Now}
+	for _, url := range urls {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url})
+	}
+	return pool
+}
+
+// candidates returns the endpoints in the order to try, the healthy ones are ordered by the balance policy, the
+// down ones are the last resort. probe is called without lock for the down endpoints whose retry time is due.
+func (p *endpointPool) candidates(probe func(e *endpoint) error) []*endpoint {
+	p.mu.Lock()
+	if len(p.endpoints) == 1 {
+		p.mu.Unlock()
+		return p.endpoints
+	}
+	now := p.now()
+	var due []*endpoint
+	for _, e := range p.endpoints {
+		if e.down && !now.Before(e.downUntil) {
+			// push the retry time, so that the concurrent requests do not probe it again
+			e.downUntil = now.Add(endpointRetryInterval)
+			due = append(due, e)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, e := range due {
+		start := p.now()
+		if err := probe(e); err == nil {
+			p.succeed(e, p.now().Sub(start))
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var healthy, down []*endpoint
+	for _, e := range p.endpoints {
+		if e.down {
+			down = append(down, e)
+		} else {
+			healthy = append(healthy, e)
+		}
+	}
+	var ordered = make([]*endpoint, 0, len(p.endpoints))
+	switch {
+	case p.balance == BalanceLeastLatency:
+		// the endpoint without latency is preferred to measure it
+		sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].latency < healthy[j].latency })
+		ordered = append(ordered, healthy...)
+	case len(healthy) > 0:
+		start := p.next % len(healthy)
+		p.next++
+		ordered = append(append(ordered, healthy[start:]...), healthy[:start]...)
+	}
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+	return append(ordered, down...)
+}
+
+func (p *endpointPool) all() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*endpoint(nil), p.endpoints...)
+}
+
+func (p *endpointPool) succeed(e *endpoint, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.down = false
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyDecay*float64(e.latency) + (1-latencyDecay)*float64(latency))
+	}
+}
+
+func (p *endpointPool) fail(e *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.down = true
+	e.downUntil = p.now().Add(endpointRetryInterval)
+}
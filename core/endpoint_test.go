@@ -0,0 +1,136 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints("a:8087, b,[::1]:8088,[::2],::3", 8086)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a:8087", "b:8086", "[::1]:8088", "[::2]:8086", "[::3]:8086"}, endpoints)
+
+	for _, hosts := range []string{"", " , ", "a:port", "a:70000"} {
+		_, err = ParseEndpoints(hosts, 8086)
+		require.Error(t, err, hosts)
+	}
+}
+
+func urlsOf(endpoints []*endpoint) []string {
+	var urls []string
+	for _, e := range endpoints {
+		urls = append(urls, e.url)
+	}
+	return urls
+}
+
+func TestEndpointPoolBalance(t *testing.T) {
+	noProbe := func(e *endpoint) error { return errors.New("unexpected probe") }
+
+	pool := newEndpointPool(BalanceRoundRobin, "a", "b", "c")
+	require.Equal(t, []string{"a", "b", "c"}, urlsOf(pool.candidates(noProbe)))
+	require.Equal(t, []string{"b", "c", "a"}, urlsOf(pool.candidates(noProbe)))
+
+	// the down endpoint is the last resort
+	pool.fail(pool.endpoints[1])
+	require.Equal(t, []string{"a", "c", "b"}, urlsOf(pool.candidates(noProbe)))
+
+	pool = newEndpointPool(BalanceLeastLatency, "a", "b", "c")
+	pool.succeed(pool.endpoints[0], 30*time.Millisecond)
+	pool.succeed(pool.endpoints[1], 10*time.Millisecond)
+	require.Equal(t, []string{"c", "b", "a"}, urlsOf(pool.candidates(noProbe)))
+	pool.succeed(pool.endpoints[2], 20*time.Millisecond)
+	require.Equal(t, []string{"b", "c", "a"}, urlsOf(pool.candidates(noProbe)))
+}
+
+func TestEndpointPoolProbe(t *testing.T) {
+	now := time.Now()
+	pool := newEndpointPool(BalanceRoundRobin, "a", "b")
+	pool.now = func() time.Time { return now }
+	pool.fail(pool.endpoints[0])
+
+	var probed []string
+	probe := func(e *endpoint) error {
+		probed = append(probed, e.url)
+		return nil
+	}
+	require.Equal(t, []string{"b", "a"}, urlsOf(pool.candidates(probe)))
+	require.Empty(t, probed)
+
+	// the endpoint serves requests again after the probe
+	now = now.Add(endpointRetryInterval)
+	require.Len(t, pool.candidates(probe), 2)
+	require.Equal(t, []string{"a"}, probed)
+	require.False(t, pool.endpoints[0].down)
+}
+
+func TestHttpClientFailover(t *testing.T) {
+	var served int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	client := &HttpClientCreator{
+		endpoints:   newEndpointPool(BalanceRoundRobin, down.URL, server.URL),
+		client:      server.Client(),
+		compression: CompressNone,
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.Write(context.Background(), "db0", "", "cpu value=1", "ns"))
+	}
+	require.Equal(t, 3, served)
+	require.True(t, client.endpoints.endpoints[0].down)
+
+	require.NoError(t, client.Ping())
+	client.endpoints = newEndpointPool(BalanceRoundRobin, down.URL)
+	require.Error(t, client.Ping())
+	require.Error(t, client.Write(context.Background(), "db0", "", "cpu value=1", "ns"))
+}
+
+func TestHttpClientNoFailoverAfterSent(t *testing.T) {
+	var served atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// the timeout request may be executed by the server, it is not sent to the next endpoint
+	client := server.Client()
+	client.Timeout = 50 * time.Millisecond
+	creator := &HttpClientCreator{
+		endpoints:   newEndpointPool(BalanceRoundRobin, server.URL, server.URL),
+		client:      client,
+		compression: CompressNone,
+	}
+	_, err := creator.Query(context.Background(), &opengemini.Query{Command: "DROP DATABASE db0"})
+	require.Error(t, err)
+	require.Equal(t, int32(1), served.Load())
+}
@@ -15,6 +15,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -28,7 +29,6 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -40,30 +40,53 @@ var _ HttpClient = (*HttpClientCreator)(nil)
 type HttpClient interface {
 	SetDebug(debug bool)
 	SetAuth(username, password string)
+	SetToken(source TokenSource)
+	SetCompression(compression string) error
 	Ping() error
 	Query(context.Context, *opengemini.Query) (*opengemini.QueryResult, error)
 	Write(ctx context.Context, database, retentionPolicy, raw, precision string) error
+	PromWrite(ctx context.Context, database, retentionPolicy string, body []byte) error
 }
 
 type HttpClientCreator struct {
-	HostPort string
-	client   *http.Client
-	basic    string
-	debug    bool
+	endpoints   *endpointPool
+	client      *http.Client
+	basic       string
+	token       TokenSource
+	debug       bool
+	compression string
 }
 
 func (h *HttpClientCreator) SetAuth(username, password string) {
 	h.basic = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
 }
 
+// SetToken authenticate the requests by the bearer token instead of basic auth
+func (h *HttpClientCreator) SetToken(source TokenSource) {
+	h.token = source
+}
+
 func (h *HttpClientCreator) SetDebug(debug bool) {
 	h.debug = debug
 }
 
+// SetCompression set the algorithm compressing the write body and the accepted query response encoding
+func (h *HttpClientCreator) SetCompression(compression string) error {
+	compression, err := ParseCompression(compression)
+	if err != nil {
+		return err
+	}
+	h.compression = compression
+	return nil
+}
+
 func NewHttpClient(cfg *CommandLineConfig) (HttpClient, error) {
 	var client = &HttpClientCreator{client: &http.Client{
 		Timeout: time.Duration(cfg.Timeout) * time.Millisecond,
 	}}
+	if err := client.SetCompression(cfg.Compression); err != nil {
+		return nil, err
+	}
 
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -130,17 +153,64 @@ func NewHttpClient(cfg *CommandLineConfig) (HttpClient, error) {
 	if cfg.Username != "" && cfg.Password != "" {
 		client.SetAuth(cfg.Username, cfg.Password)
 	}
+	token, err := NewTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		client.SetToken(token)
+	}
 
-	client.HostPort = schema + "://" + cfg.Host + ":" + strconv.FormatInt(int64(cfg.Port), 10)
+	endpoints, err := ParseEndpoints(cfg.Host, cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.UnixSocket != "" {
+		// all requests are sent to the socket
+		endpoints = endpoints[:1]
+	}
+	balance, err := ValidateBalance(cfg.Balance)
+	if err != nil {
+		return nil, err
+	}
+	var urls = make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		urls = append(urls, schema+"://"+endpoint)
+	}
+	client.endpoints = newEndpointPool(balance, urls...)
 
 	client.client.Transport = transport
 	return client, nil
 }
 
-// Ping will check to see if the server is up
+// Ping will check to see if the servers are up and track their health, it fails only if all of them are down
 func (h *HttpClientCreator) Ping() error {
-	var urlPath = h.HostPort + "/ping"
-	response, err := h.innerRequest(context.Background(), http.MethodGet, urlPath, nil)
+	var endpoints = h.endpoints.all()
+	var errs []error
+	for _, e := range endpoints {
+		start := time.Now()
+		if err := h.ping(context.Background(), e); err != nil {
+			h.endpoints.fail(e)
+			if len(endpoints) > 1 {
+				err = fmt.Errorf("%s: %w", e.url, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		h.endpoints.succeed(e, time.Since(start))
+	}
+	if len(errs) == len(endpoints) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (h *HttpClientCreator) ping(ctx context.Context, e *endpoint) error {
+	request, err := h.newRequest(ctx, http.MethodGet, e.url+"/ping", nil)
+	if err != nil {
+		return err
+	}
+	response, err := h.client.Do(request)
 	if err != nil {
 		return err
 	}
@@ -151,85 +221,205 @@ func (h *HttpClientCreator) Ping() error {
 	return nil
 }
 
-func (h *HttpClientCreator) Query(ctx context.Context, query *opengemini.Query) (*opengemini.QueryResult, error) {
-	urlPath := h.HostPort + "/query"
+// probe ping the down endpoint before it serves requests again
+func (h *HttpClientCreator) probe(e *endpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), endpointProbeTimeout)
+	defer cancel()
+	return h.ping(ctx, e)
+}
 
+func (h *HttpClientCreator) Query(ctx context.Context, query *opengemini.Query) (*opengemini.QueryResult, error) {
 	var queryValues = make(url.Values)
 	queryValues.Add("db", query.Database)
 	queryValues.Add("rp", query.RetentionPolicy)
 	queryValues.Add("q", query.Command)
 	queryValues.Add("epoch", query.Precision.Epoch())
 
-	response, err := h.innerRequest(ctx, http.MethodPost, urlPath, strings.NewReader(queryValues.Encode()))
+	// the transport only decompress gzip transparently when Accept-Encoding is not set by the request,
+	// and never for unix socket
+	var headers []func(http.Header)
+	if h.compression != CompressNone {
+		headers = append(headers, func(header http.Header) {
+			header.Set("Accept-Encoding", acceptEncoding(h.compression))
+		})
+	}
+	response, err := h.innerRequest(ctx, http.MethodPost, "/query", strings.NewReader(queryValues.Encode()), headers...)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
-	data, err := io.ReadAll(response.Body)
+	data, err := decompressBody(response)
 	if err != nil {
 		return nil, err
 	}
 	if response.StatusCode != http.StatusOK {
 		return nil, errors.New("response status_code: " + response.Status + ", body: " + string(data))
 	}
+	// the numbers are kept as json.Number, int64 loses precision by float64
 	var qr = new(opengemini.QueryResult)
-	err = json.Unmarshal(data, qr)
-	if err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(qr); err != nil {
 		return nil, err
 	}
 	return qr, nil
 }
 
 func (h *HttpClientCreator) Write(ctx context.Context, database, retentionPolicy, raw, precision string) error {
-	urlPath := h.HostPort + "/write"
-	u, err := url.Parse(urlPath)
+	var writeValues = make(url.Values)
+	writeValues.Add("db", database)
+	writeValues.Add("rp", retentionPolicy)
+	writeValues.Add("precision", precision)
+	path := "/write?" + writeValues.Encode()
+
+	if h.compression == CompressNone {
+		response, err := h.innerRequest(ctx, http.MethodPost, path, strings.NewReader(raw))
+		if err != nil {
+			return err
+		}
+		return checkWriteStatus(response)
+	}
+	body, err := compressBody(h.compression, []byte(raw))
+	if err != nil {
+		return err
+	}
+	response, err := h.innerRequest(ctx, http.MethodPost, path, bytes.NewReader(body), func(header http.Header) {
+		header.Set("Content-Encoding", h.compression)
+	})
 	if err != nil {
 		return err
 	}
+	return checkWriteStatus(response)
+}
 
+// PromWrite write the snappy compressed prometheus remote write request
+func (h *HttpClientCreator) PromWrite(ctx context.Context, database, retentionPolicy string, body []byte) error {
 	var writeValues = make(url.Values)
 	writeValues.Add("db", database)
 	writeValues.Add("rp", retentionPolicy)
-	writeValues.Add("precision", precision)
-	u.RawQuery = writeValues.Encode()
 
-	response, err := h.innerRequest(ctx, http.MethodPost, u.String(), strings.NewReader(raw))
+	response, err := h.innerRequest(ctx, http.MethodPost, "/api/v1/prom/write?"+writeValues.Encode(), bytes.NewReader(body), func(header http.Header) {
+		header.Set("Content-Type", "application/x-protobuf")
+		header.Set("Content-Encoding", "snappy")
+		header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	})
 	if err != nil {
 		return err
 	}
-	defer response.Body.Close()
+	return checkWriteStatus(response)
+}
 
+func checkWriteStatus(response *http.Response) error {
+	defer response.Body.Close()
 	if response.StatusCode != http.StatusNoContent {
-		return errors.New("write failed: " + response.Status)
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+		return &WriteError{StatusCode: response.StatusCode, Status: response.Status, Body: string(body)}
 	}
 	return nil
 }
 
-func (h *HttpClientCreator) innerRequest(ctx context.Context, method, urlPath string, reader io.Reader) (*http.Response, error) {
-	request, err := http.NewRequestWithContext(ctx, method, urlPath, reader)
+// maxErrorBodySize limit the size of error body read from write response
+const maxErrorBodySize = 4096
+
+// WriteError is returned when the server rejects the write request
+type WriteError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *WriteError) Error() string {
+	return "write failed: " + e.Status
+}
+
+// Retryable returns false if the request is rejected because of the data, such as bad line protocol
+func (e *WriteError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return false
+	}
+	return true
+}
+
+// innerRequest send the request to the endpoints selected by the balance policy, the next endpoint is tried on the
+// failure of connecting it
+func (h *HttpClientCreator) innerRequest(ctx context.Context, method, path string, body io.Reader, headers ...func(http.Header)) (*http.Response, error) {
+	var lastErr error
+	for _, e := range h.endpoints.candidates(h.probe) {
+		if lastErr != nil {
+			// rewind the body consumed by the failed endpoint, the body cannot be sent again without it
+			seeker, ok := body.(io.Seeker)
+			if body != nil && !ok {
+				return nil, lastErr
+			}
+			if ok {
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return nil, lastErr
+				}
+			}
+		}
+		request, err := h.newRequest(ctx, method, e.url+path, body, headers...)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.debug {
+			dumpRequest, _ := httputil.DumpRequest(request, true)
+			fmt.Printf("---------- REQUEST DEBUG ----------\n%s\n---------- REQUEST DEBUG ----------\n", string(dumpRequest))
+		}
+
+		start := time.Now()
+		response, err := h.client.Do(request)
+		if err != nil {
+			// the request may be executed if it is sent, such as the timeout of DROP or SELECT INTO,
+			// only the request failed to connect is sent to the next endpoint
+			if ctx.Err() != nil || !isDialError(err) {
+				return nil, err
+			}
+			h.endpoints.fail(e)
+			lastErr = err
+			continue
+		}
+		h.endpoints.succeed(e, time.Since(start))
+
+		if h.debug {
+			dumpResponse, _ := httputil.DumpResponse(response, true)
+			fmt.Printf("---------- RESPONSE DEBUG ----------\n%s\nserved by: %s\n---------- RESPONSE DEBUG ----------\n", string(dumpResponse), e.url)
+		}
+		return response, nil
+	}
+	return nil, lastErr
+}
+
+// isDialError reports whether the connection is not established, so that the request is not sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (h *HttpClientCreator) newRequest(ctx context.Context, method, urlPath string, body io.Reader, headers ...func(http.Header)) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, urlPath, body)
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Set("User-Agent", "opengemini-cli")
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	if h.basic != "" {
+	if h.token != nil {
+		token, err := h.token.Token()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	} else if h.basic != "" {
 		request.Header.Set("Authorization", "Basic "+h.basic)
 	}
-
-	if h.debug {
-		dumpRequest, _ := httputil.DumpRequest(request, true)
-		fmt.Printf("---------- REQUEST DEBUG ----------\n%s\n---------- REQUEST DEBUG ----------\n", string(dumpRequest))
+	for _, header := range headers {
+		header(request.Header)
 	}
-
-	response, err := h.client.Do(request)
-
-	if h.debug {
-		dumpResponse, _ := httputil.DumpResponse(response, true)
-		fmt.Printf("---------- RESPONSE DEBUG ----------\n%s\n---------- RESPONSE DEBUG ----------\n", string(dumpResponse))
-	}
-
-	return response, err
+	return request, nil
 }
 
 type CertificateManager struct {
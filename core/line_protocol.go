@@ -17,9 +17,12 @@ package core
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/openGemini/opengemini-client-go/opengemini"
 	"github.com/valyala/fastjson/fastfloat"
@@ -42,12 +45,15 @@ type LineProtocolParser struct {
 	points       []*opengemini.Point
 	currentPoint *opengemini.Point
 	currentState LineProtocolState
-	currentKey   string
-	currentValue string
+	measurement  strings.Builder
+	currentKey   strings.Builder
+	currentValue strings.Builder
 	currentTime  string
 	escape       bool
 	quota        bool
+	quoted       bool // the current field value is a string
 	bracket      bool
+	err          error
 }
 
 func NewLineProtocolParser(raw string) *LineProtocolParser {
@@ -73,19 +79,34 @@ func (p *LineProtocolParser) Parse(timeMultiplier int64) ([]*opengemini.Point, e
 	return p.points, nil
 }
 
+// ParseLine parse one line without the buffer of Parse, so that the parser can be reused for
+// every line. The point is nil if the line is empty or comment.
+func (p *LineProtocolParser) ParseLine(line string, timeMultiplier int64) (*opengemini.Point, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+	return p.parse(line, timeMultiplier)
+}
+
 // parse "average_temperature,location=coyote_creek degrees=74 1567623456"
 func (p *LineProtocolParser) parse(line string, timeMultiplier int64) (*opengemini.Point, error) {
 	// ignore comment
 	if line[0] == '#' {
 		return nil, nil
 	}
+	p.escape, p.quota, p.quoted, p.bracket = false, false, false, false
+	p.err = nil
+	p.measurement.Reset()
+	p.currentKey.Reset()
+	p.currentValue.Reset()
+	p.currentTime = ""
 	p.currentState = Measurement
 	p.currentPoint = &opengemini.Point{Tags: make(map[string]string), Fields: make(map[string]interface{})}
 
 	// parse timestamp
-	fds := strings.Split(line, " ")
-	if len(fds) >= 3 {
-		tsp := strings.TrimSpace(fds[len(fds)-1])
+	if strings.Count(line, " ") >= 2 {
+		tsp := strings.TrimSpace(line[strings.LastIndexByte(line, ' ')+1:])
 		if checkIsDigit(tsp) {
 			p.currentTime = tsp
 		}
@@ -120,6 +141,9 @@ func (p *LineProtocolParser) parse(line string, timeMultiplier int64) (*opengemi
 				continue
 			}
 			p.quota = true
+			if p.currentState == FieldValue {
+				p.quoted = true
+			}
 		case ',':
 			if p.escape {
 				p.appendToken(token)
@@ -149,7 +173,7 @@ func (p *LineProtocolParser) parse(line string, timeMultiplier int64) (*opengemi
 				p.escape = false
 				continue
 			}
-			if p.currentKey != "" {
+			if p.currentKey.Len() != 0 {
 				p.appendTagOrField()
 			}
 			if p.currentState == Timestamp {
@@ -188,12 +212,17 @@ func (p *LineProtocolParser) parse(line string, timeMultiplier int64) (*opengemi
 		}
 	}
 
-	if p.currentKey != "" {
+	if p.currentKey.Len() != 0 {
 		p.appendTagOrField()
 	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.currentPoint.Measurement = p.measurement.String()
+	p.measurement.Reset()
 
 	if p.currentTime == "" {
-		p.currentPoint.Timestamp = time.Now().UnixNano()
+		p.currentPoint.Timestamp = NoTimestamp
 	} else {
 		p.currentPoint.Timestamp = int64(fastfloat.ParseBestEffort(p.currentTime)) * timeMultiplier
 	}
@@ -206,14 +235,13 @@ func (p *LineProtocolParser) parse(line string, timeMultiplier int64) (*opengemi
 }
 
 func (p *LineProtocolParser) appendToken(token rune) {
-	word := string(token)
 	switch p.currentState {
 	case Measurement:
-		p.currentPoint.Measurement += word
+		p.measurement.WriteRune(token)
 	case TagKey, FieldKey:
-		p.currentKey += word
+		p.currentKey.WriteRune(token)
 	case TagValue, FieldValue:
-		p.currentValue += word
+		p.currentValue.WriteRune(token)
 	default:
 
 	}
@@ -222,14 +250,49 @@ func (p *LineProtocolParser) appendToken(token rune) {
 func (p *LineProtocolParser) appendTagOrField() {
 	switch p.currentState {
 	case TagKey, TagValue:
-		p.currentPoint.Tags[p.currentKey] = p.currentValue
+		p.currentPoint.Tags[p.currentKey.String()] = p.currentValue.String()
 	case FieldKey, FieldValue:
-		p.currentPoint.Fields[p.currentKey] = p.currentValue
+		value, err := parseFieldValue(p.currentValue.String(), p.quoted)
+		if err != nil && p.err == nil {
+			p.err = fmt.Errorf("field %s: %w", p.currentKey.String(), err)
+		}
+		p.currentPoint.Fields[p.currentKey.String()] = value
 	default:
 
 	}
-	p.currentKey = ""
-	p.currentValue = ""
+	p.currentKey.Reset()
+	p.currentValue.Reset()
+	p.quoted = false
+}
+
+// parseFieldValue returns the typed field value, float, integer with suffix 'i', unsigned integer with suffix
+// 'u', boolean or the quoted string
+func parseFieldValue(raw string, quoted bool) (any, error) {
+	if quoted {
+		return raw, nil
+	}
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+	if n := len(raw); n > 1 {
+		switch raw[n-1] {
+		case 'i':
+			if v, err := strconv.ParseInt(raw[:n-1], 10, 64); err == nil {
+				return v, nil
+			}
+		case 'u':
+			if v, err := strconv.ParseUint(raw[:n-1], 10, 64); err == nil {
+				return v, nil
+			}
+		}
+	}
+	if v, err := strconv.ParseFloat(raw, 64); err == nil {
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid field value %q", raw)
 }
 
 func checkIsDigit(s string) bool {
@@ -240,3 +303,100 @@ func checkIsDigit(s string) bool {
 	}
 	return true
 }
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, ` `, `\ `, `=`, `\=`)
+	stringFieldEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// NoTimestamp is the timestamp of the point encoded without timestamp, so that the server assigns
+// its time, zero is the valid epoch timestamp
+const NoTimestamp int64 = math.MinInt64
+
+// EncodeLineProtocol encode points to line protocol, tags and fields are sorted by key
+// so that the output is stable, the timestamp is written as it is unless it is NoTimestamp.
+func EncodeLineProtocol(points []*opengemini.Point) (string, error) {
+	var builder strings.Builder
+	for _, point := range points {
+		if point == nil || point.Measurement == "" || len(point.Fields) == 0 {
+			continue
+		}
+		builder.WriteString(measurementEscaper.Replace(point.Measurement))
+		for _, key := range SortedKeys(point.Tags) {
+			if point.Tags[key] == "" { // empty tag value is not allowed
+				continue
+			}
+			builder.WriteByte(',')
+			builder.WriteString(tagEscaper.Replace(key))
+			builder.WriteByte('=')
+			builder.WriteString(tagEscaper.Replace(point.Tags[key]))
+		}
+		for idx, key := range SortedKeys(point.Fields) {
+			if idx == 0 {
+				builder.WriteByte(' ')
+			} else {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(tagEscaper.Replace(key))
+			builder.WriteByte('=')
+			if err := writeFieldValue(&builder, point.Fields[key]); err != nil {
+				return "", fmt.Errorf("field %s: %w", key, err)
+			}
+		}
+		if point.Timestamp != NoTimestamp {
+			builder.WriteByte(' ')
+			builder.WriteString(strconv.FormatInt(point.Timestamp, 10))
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String(), nil
+}
+
+func writeFieldValue(builder *strings.Builder, value any) error {
+	switch v := value.(type) {
+	case string:
+		builder.WriteByte('"')
+		builder.WriteString(stringFieldEscaper.Replace(v))
+		builder.WriteByte('"')
+	case int:
+		builder.WriteString(strconv.FormatInt(int64(v), 10) + "i")
+	case int8:
+		builder.WriteString(strconv.FormatInt(int64(v), 10) + "i")
+	case int16:
+		builder.WriteString(strconv.FormatInt(int64(v), 10) + "i")
+	case int32:
+		builder.WriteString(strconv.FormatInt(int64(v), 10) + "i")
+	case int64:
+		builder.WriteString(strconv.FormatInt(v, 10) + "i")
+	case uint:
+		builder.WriteString(strconv.FormatUint(uint64(v), 10) + "u")
+	case uint8:
+		builder.WriteString(strconv.FormatUint(uint64(v), 10) + "u")
+	case uint16:
+		builder.WriteString(strconv.FormatUint(uint64(v), 10) + "u")
+	case uint32:
+		builder.WriteString(strconv.FormatUint(uint64(v), 10) + "u")
+	case uint64:
+		builder.WriteString(strconv.FormatUint(v, 10) + "u")
+	case float32:
+		builder.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		builder.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		builder.WriteString(strconv.FormatBool(v))
+	default:
+		return fmt.Errorf("unsupported field value type %T", value)
+	}
+	return nil
+}
+
+// SortedKeys returns the keys of the map in ascending order
+func SortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
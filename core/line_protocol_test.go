@@ -17,6 +17,8 @@ package core
 import (
 	"reflect"
 	"testing"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
 )
 
 func TestLineProtocolParser_Parse(t *testing.T) {
@@ -34,7 +36,7 @@ func TestLineProtocolParser_Parse(t *testing.T) {
 			wantErr:    false,
 			wantMst:    "mst",
 			wantTags:   map[string]string{"t1": "1"},
-			wantFields: map[string]interface{}{"v1": "1"},
+			wantFields: map[string]interface{}{"v1": float64(1)},
 		},
 		{
 			name:       "escape mst",
@@ -42,7 +44,20 @@ func TestLineProtocolParser_Parse(t *testing.T) {
 			wantErr:    false,
 			wantMst:    `mst,1`,
 			wantTags:   map[string]string{"t1": "1"},
-			wantFields: map[string]interface{}{"v1": "1"},
+			wantFields: map[string]interface{}{"v1": float64(1)},
+		},
+		{
+			name:       "typed fields",
+			raw:        `mst,t1=1 f=1.5,i=-3i,u=3u,b=t,s="1 2",e="" 123`,
+			wantErr:    false,
+			wantMst:    "mst",
+			wantTags:   map[string]string{"t1": "1"},
+			wantFields: map[string]interface{}{"f": 1.5, "i": int64(-3), "u": uint64(3), "b": true, "s": "1 2", "e": ""},
+		},
+		{
+			name:    "invalid field",
+			raw:     `mst v1=abc 123`,
+			wantErr: true,
 		},
 		{
 			name:       "tag array",
@@ -50,7 +65,7 @@ func TestLineProtocolParser_Parse(t *testing.T) {
 			wantErr:    false,
 			wantMst:    "mst",
 			wantTags:   map[string]string{"t1": "[t1,t2]"},
-			wantFields: map[string]interface{}{"v1": "1"},
+			wantFields: map[string]interface{}{"v1": float64(1)},
 		},
 	}
 	for _, tt := range tests {
@@ -61,6 +76,9 @@ func TestLineProtocolParser_Parse(t *testing.T) {
 				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if !reflect.DeepEqual(got[0].Tags, tt.wantTags) {
 				t.Errorf("parse() got = %v, want %v", got[0].Tags, tt.wantTags)
 				return
@@ -72,3 +90,42 @@ func TestLineProtocolParser_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeLineProtocol(t *testing.T) {
+	points := []*opengemini.Point{
+		{
+			Measurement: "m 1,a",
+			Tags:        map[string]string{"b": "x=1", "a": "y z", "empty": ""},
+			Fields:      map[string]interface{}{"s": `say "hi" \`, "i": int64(-1), "u": uint64(2), "f": 1.5, "b": true},
+			Timestamp:   123,
+		},
+		{Measurement: "no_field", Fields: map[string]interface{}{}},
+		{Measurement: "m2", Fields: map[string]interface{}{"v": 1.0}, Timestamp: NoTimestamp},
+		{Measurement: "epoch", Fields: map[string]interface{}{"v": 1.0}},
+	}
+	lines, err := EncodeLineProtocol(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `m\ 1\,a,a=y\ z,b=x\=1 b=true,f=1.5,i=-1i,s="say \"hi\" \\",u=2u 123` + "\nm2 v=1\nepoch v=1 0\n"
+	if lines != expect {
+		t.Errorf("EncodeLineProtocol() got = %s, want %s", lines, expect)
+	}
+
+	// the line without timestamp is encoded without timestamp
+	parsed, err := NewLineProtocolParser("m v=1\nm v=2 0").Parse(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed[0].Timestamp != NoTimestamp {
+		t.Errorf("Parse() got timestamp %d, want NoTimestamp", parsed[0].Timestamp)
+	}
+	if lines, _ = EncodeLineProtocol(parsed); lines != "m v=1\nm v=2 0\n" {
+		t.Errorf("EncodeLineProtocol() got = %s, want the lines parsed", lines)
+	}
+
+	_, err = EncodeLineProtocol([]*opengemini.Point{{Measurement: "m", Fields: map[string]interface{}{"v": []int{1}}}})
+	if err == nil {
+		t.Error("EncodeLineProtocol() expect error for unsupported field type")
+	}
+}
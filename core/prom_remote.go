@@ -0,0 +1,279 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// the field numbers of prometheus remote write protobuf
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+const (
+	promWriteRequestTimeSeries protowire.Number = 1
+	promTimeSeriesLabels       protowire.Number = 1
+	promTimeSeriesSamples      protowire.Number = 2
+	promLabelName              protowire.Number = 1
+	promLabelValue             protowire.Number = 2
+	promSampleValue            protowire.Number = 1
+	promSampleTimestamp        protowire.Number = 2
+)
+
+const promMetricNameLabel = "__name__"
+
+// PromLabel is the label of prometheus time series
+type PromLabel struct {
+	Name  string
+	Value string
+}
+
+// PromSample is the sample of prometheus time series, the timestamp is millisecond
+type PromSample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// PromTimeSeries is the time series of prometheus remote write, the labels are sorted by name
+type PromTimeSeries struct {
+	Labels  []PromLabel
+	Samples []PromSample
+}
+
+// PromSeries convert the points to prometheus time series, the samples of the same series are
+// merged into one time series and the duplicated samples of the same time keep the last value.
+// The metric name is the measurement for valueField, otherwise `<measurement>_<field>`. The
+// timestamp of point is nanosecond, the point of NoTimestamp is sampled at the current time.
+func PromSeries(points []*opengemini.Point, valueField string) ([]*PromTimeSeries, error) {
+	var series = make(map[string]*PromTimeSeries)
+	var samples = make(map[string]map[int64]int) // the index of sample by series and timestamp
+	var keys []string
+	var now = time.Now().UnixNano()
+	for _, point := range points {
+		if point == nil || point.Measurement == "" {
+			continue
+		}
+		var timestamp = point.Timestamp
+		if timestamp == NoTimestamp {
+			timestamp = now
+		}
+		for _, field := range SortedKeys(point.Fields) {
+			value, err := toPromValue(point.Fields[field])
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %s: %w", field, point.Measurement, err)
+			}
+			var name = point.Measurement
+			if field != valueField {
+				name += "_" + field
+			}
+			var labels = []PromLabel{{Name: promMetricNameLabel, Value: name}}
+			for _, key := range SortedKeys(point.Tags) {
+				if key == promMetricNameLabel || point.Tags[key] == "" {
+					continue
+				}
+				labels = append(labels, PromLabel{Name: key, Value: point.Tags[key]})
+			}
+			sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+			key := promSeriesKey(labels)
+			ts, ok := series[key]
+			if !ok {
+				ts = &PromTimeSeries{Labels: labels}
+				series[key] = ts
+				samples[key] = make(map[int64]int)
+				keys = append(keys, key)
+			}
+			var sample = PromSample{Value: value, Timestamp: timestamp / 1e6}
+			if idx, ok := samples[key][sample.Timestamp]; ok {
+				ts.Samples[idx] = sample
+				continue
+			}
+			samples[key][sample.Timestamp] = len(ts.Samples)
+			ts.Samples = append(ts.Samples, sample)
+		}
+	}
+	var result = make([]*PromTimeSeries, 0, len(keys))
+	for _, key := range keys {
+		ts := series[key]
+		sort.SliceStable(ts.Samples, func(i, j int) bool { return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp })
+		result = append(result, ts)
+	}
+	return result, nil
+}
+
+func promSeriesKey(labels []PromLabel) string {
+	var builder strings.Builder
+	for _, label := range labels {
+		builder.WriteString(label.Name)
+		builder.WriteByte(0)
+		builder.WriteString(label.Value)
+		builder.WriteByte(0)
+	}
+	return builder.String()
+}
+
+func toPromValue(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string: // numeric string such as '2i' or 'true'
+		if f, err := strconv.ParseFloat(strings.TrimRight(v, "iu"), 64); err == nil {
+			return f, nil
+		}
+		if b, err := strconv.ParseBool(v); err == nil {
+			return toPromValue(b)
+		}
+		return 0, fmt.Errorf("invalid sample value %q", v)
+	}
+	return 0, fmt.Errorf("unsupported sample value type %T", value)
+}
+
+// EncodePromRemoteWrite encode the time series to snappy compressed remote write request
+func EncodePromRemoteWrite(series []*PromTimeSeries) []byte {
+	var request []byte
+	for _, ts := range series {
+		var message []byte
+		for _, label := range ts.Labels {
+			var buf []byte
+			buf = protowire.AppendTag(buf, promLabelName, protowire.BytesType)
+			buf = protowire.AppendString(buf, label.Name)
+			buf = protowire.AppendTag(buf, promLabelValue, protowire.BytesType)
+			buf = protowire.AppendString(buf, label.Value)
+			message = protowire.AppendTag(message, promTimeSeriesLabels, protowire.BytesType)
+			message = protowire.AppendBytes(message, buf)
+		}
+		for _, sample := range ts.Samples {
+			var buf []byte
+			buf = protowire.AppendTag(buf, promSampleValue, protowire.Fixed64Type)
+			buf = protowire.AppendFixed64(buf, math.Float64bits(sample.Value))
+			buf = protowire.AppendTag(buf, promSampleTimestamp, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(sample.Timestamp))
+			message = protowire.AppendTag(message, promTimeSeriesSamples, protowire.BytesType)
+			message = protowire.AppendBytes(message, buf)
+		}
+		request = protowire.AppendTag(request, promWriteRequestTimeSeries, protowire.BytesType)
+		request = protowire.AppendBytes(request, message)
+	}
+	return snappy.Encode(nil, request)
+}
+
+// DecodePromRemoteWrite decode the snappy compressed remote write request, the unknown fields are skipped
+func DecodePromRemoteWrite(body []byte) ([]*PromTimeSeries, error) {
+	request, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var series []*PromTimeSeries
+	err = consumeMessage(request, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != promWriteRequestTimeSeries || typ != protowire.BytesType {
+			return nil
+		}
+		var ts = new(PromTimeSeries)
+		series = append(series, ts)
+		return consumeMessage(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+			switch {
+			case num == promTimeSeriesLabels && typ == protowire.BytesType:
+				var label PromLabel
+				err := consumeMessage(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+					switch num {
+					case promLabelName:
+						label.Name = string(value)
+					case promLabelValue:
+						label.Value = string(value)
+					}
+					return nil
+				})
+				ts.Labels = append(ts.Labels, label)
+				return err
+			case num == promTimeSeriesSamples && typ == protowire.BytesType:
+				var sample PromSample
+				err := consumeMessage(value, func(num protowire.Number, typ protowire.Type, _ []byte, n uint64) error {
+					switch {
+					case num == promSampleValue && typ == protowire.Fixed64Type:
+						sample.Value = math.Float64frombits(n)
+					case num == promSampleTimestamp && typ == protowire.VarintType:
+						sample.Timestamp = int64(n)
+					}
+					return nil
+				})
+				ts.Samples = append(ts.Samples, sample)
+				return err
+			}
+			return nil
+		})
+	})
+	return series, err
+}
+
+// consumeMessage iterate the fields of protobuf message, the bytes field is passed as value and
+// the numeric field as n
+func consumeMessage(message []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, n uint64) error) error {
+	for len(message) > 0 {
+		num, typ, length := protowire.ConsumeTag(message)
+		if length < 0 {
+			return protowire.ParseError(length)
+		}
+		message = message[length:]
+		var value []byte
+		var n uint64
+		switch typ {
+		case protowire.BytesType:
+			value, length = protowire.ConsumeBytes(message)
+		case protowire.VarintType:
+			n, length = protowire.ConsumeVarint(message)
+		case protowire.Fixed64Type:
+			n, length = protowire.ConsumeFixed64(message)
+		default:
+			length = protowire.ConsumeFieldValue(num, typ, message)
+		}
+		if length < 0 {
+			return protowire.ParseError(length)
+		}
+		message = message[length:]
+		if err := fn(num, typ, value, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromRemoteWrite(t *testing.T) {
+	points := []*opengemini.Point{
+		{Measurement: "up", Tags: map[string]string{"job": "node"}, Fields: map[string]any{"value": 1.0}, Timestamp: 2e6},
+		{Measurement: "up", Tags: map[string]string{"job": "node"}, Fields: map[string]any{"value": 0.0}, Timestamp: 1e6},
+		{Measurement: "up", Tags: map[string]string{"job": "node"}, Fields: map[string]any{"value": 3.0}, Timestamp: 1e6},
+		{Measurement: "rpc", Tags: map[string]string{"__name__": "ignored"}, Fields: map[string]any{"sum": int64(5), "count": true}, Timestamp: 3e6},
+	}
+	series, err := PromSeries(points, "value")
+	require.NoError(t, err)
+	expected := []*PromTimeSeries{
+		{
+			Labels:  []PromLabel{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []PromSample{{Value: 3, Timestamp: 1}, {Value: 1, Timestamp: 2}},
+		},
+		{Labels: []PromLabel{{Name: "__name__", Value: "rpc_count"}}, Samples: []PromSample{{Value: 1, Timestamp: 3}}},
+		{Labels: []PromLabel{{Name: "__name__", Value: "rpc_sum"}}, Samples: []PromSample{{Value: 5, Timestamp: 3}}},
+	}
+	require.Equal(t, expected, series)
+
+	decoded, err := DecodePromRemoteWrite(EncodePromRemoteWrite(series))
+	require.NoError(t, err)
+	require.Equal(t, expected, decoded)
+
+	// the raw values of line protocol parser
+	series, err = PromSeries([]*opengemini.Point{{Measurement: "m", Fields: map[string]any{"value": "2i", "ok": "true"}}}, "value")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, series[0].Samples[0].Value)
+	require.Equal(t, 2.0, series[1].Samples[0].Value)
+
+	_, err = PromSeries([]*opengemini.Point{{Measurement: "m", Fields: map[string]any{"value": "text"}}}, "value")
+	require.Error(t, err)
+	_, err = DecodePromRemoteWrite([]byte("not snappy"))
+	require.Error(t, err)
+}
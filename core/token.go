@@ -0,0 +1,202 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenCommandTimeout limit the running time of --token-command
+	tokenCommandTimeout = 30 * time.Second
+	// tokenCommandTTL is the lifetime of the minted token which is not a JWT with exp claim
+	tokenCommandTTL = time.Minute
+	// tokenRefreshAhead mint a new token before the JWT expires
+	tokenRefreshAhead = 30 * time.Second
+)
+
+// TokenSource provides the bearer token of each request
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is the token given by --token or the auth token statement
+type StaticToken string
+
+func (t StaticToken) Token() (string, error) {
+	return string(t), nil
+}
+
+// NewTokenSource create the token source by --token, --token-file or --token-command, returns nil if none of them
+// is specified
+func NewTokenSource(cfg *CommandLineConfig) (TokenSource, error) {
+	var specified int
+	for _, option := range []string{cfg.Token, cfg.TokenFile, cfg.TokenCommand} {
+		if option != "" {
+			specified++
+		}
+	}
+	if specified > 1 {
+		return nil, errors.New("only one of --token, --token-file and --token-command can be specified")
+	}
+	switch {
+	case cfg.Token != "":
+		return StaticToken(cfg.Token), nil
+	case cfg.TokenFile != "":
+		source := &fileToken{path: cfg.TokenFile}
+		if _, err := source.Token(); err != nil {
+			return nil, err
+		}
+		return source, nil
+	case cfg.TokenCommand != "":
+		return &commandToken{command: cfg.TokenCommand, now: time.Now}, nil
+	}
+	return nil, nil
+}
+
+// fileToken reload the token file when it is modified, so that the token can be rotated by other tools
+type fileToken struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileToken) Token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stat, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("read token file failed: %w", err)
+	}
+	if f.token != "" && stat.ModTime().Equal(f.modTime) && stat.Size() == f.size {
+		return f.token, nil
+	}
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("read token file failed: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+	f.token, f.modTime, f.size = token, stat.ModTime(), stat.Size()
+	return f.token, nil
+}
+
+// commandToken run the external program to mint the short-lived token, the token is cached until it is about to
+// expire by the exp claim of JWT, or for tokenCommandTTL
+type commandToken struct {
+	command string
+	now     func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (c *commandToken) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.token != "" && now.Before(c.expiry) {
+		return c.token, nil
+	}
+	token, err := runTokenCommand(c.command)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiry = now.Add(tokenCommandTTL)
+	if expiry, ok := jwtExpiry(token); ok {
+		c.expiry = expiry.Add(-tokenRefreshAhead)
+		if !c.expiry.After(now) {
+			c.expiry = expiry
+		}
+	}
+	return c.token, nil
+}
+
+func runTokenCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("run token command failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", errors.New("token command outputs empty token")
+	}
+	return token, nil
+}
+
+// jwtExpiry returns the exp claim of JWT without verifying the signature, the server verifies it
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// TokenCredentials attach the bearer token to the metadata of gRPC requests
+type TokenCredentials struct {
+	Source TokenSource
+}
+
+func (t TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := t.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity allows the token over plain connection, as the http requests do
+func (t TokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenSource(t *testing.T) {
+	source, err := NewTokenSource(&CommandLineConfig{})
+	require.NoError(t, err)
+	require.Nil(t, source)
+
+	source, err = NewTokenSource(&CommandLineConfig{Token: "abc"})
+	require.NoError(t, err)
+	token, err := source.Token()
+	require.NoError(t, err)
+	require.Equal(t, "abc", token)
+
+	_, err = NewTokenSource(&CommandLineConfig{Token: "abc", TokenCommand: "echo abc"})
+	require.Error(t, err)
+	_, err = NewTokenSource(&CommandLineConfig{TokenFile: filepath.Join(t.TempDir(), "missing")})
+	require.Error(t, err)
+}
+
+func TestFileToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0600))
+	source, err := NewTokenSource(&CommandLineConfig{TokenFile: path})
+	require.NoError(t, err)
+	token, err := source.Token()
+	require.NoError(t, err)
+	require.Equal(t, "first", token)
+
+	// the rotated token is reloaded
+	require.NoError(t, os.WriteFile(path, []byte("second-token\n"), 0600))
+	token, err = source.Token()
+	require.NoError(t, err)
+	require.Equal(t, "second-token", token)
+}
+
+func TestCommandToken(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the token command is run by sh")
+	}
+	now := time.Unix(1700000000, 0)
+	counter := filepath.Join(t.TempDir(), "counter")
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"cli","exp":%d}`, now.Add(time.Hour).Unix())))
+	source := &commandToken{
+		command: fmt.Sprintf("echo x >> %s; echo header.%s.signature", counter, payload),
+		now:     func() time.Time { return now },
+	}
+	minted := func() int {
+		content, err := os.ReadFile(counter)
+		require.NoError(t, err)
+		return len(content) / 2
+	}
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	require.Equal(t, "header."+payload+".signature", token)
+	_, err = source.Token()
+	require.NoError(t, err)
+	require.Equal(t, 1, minted())
+
+	// mint a new token before the JWT expires
+	now = now.Add(time.Hour - tokenRefreshAhead)
+	_, err = source.Token()
+	require.NoError(t, err)
+	require.Equal(t, 2, minted())
+
+	_, err = (&commandToken{command: "exit 1", now: time.Now}).Token()
+	require.Error(t, err)
+}
+
+func TestHttpClientToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &HttpClientCreator{endpoints: newEndpointPool(BalanceRoundRobin, server.URL), client: server.Client(), compression: CompressNone}
+	client.SetAuth("user", "pass")
+	require.NoError(t, client.Ping())
+	require.Equal(t, "Basic dXNlcjpwYXNz", authorization)
+
+	client.SetToken(StaticToken("abc"))
+	require.NoError(t, client.Ping())
+	require.Equal(t, "Bearer abc", authorization)
+
+	metadata, err := TokenCredentials{Source: StaticToken("abc")}.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"authorization": "Bearer abc"}, metadata)
+}
@@ -82,7 +82,9 @@ type ChunkSizeStatement struct {
 
 func (s *ChunkSizeStatement) stmt() {}
 
+// AuthStatement prompts for username and password, or the bearer token if Method is token
 type AuthStatement struct {
+	Method string
 }
 
 func (s *AuthStatement) stmt() {}
@@ -108,3 +110,16 @@ func (s *PromptStatement) stmt() {}
 type VerticalStatement struct{}
 
 func (s *VerticalStatement) stmt() {}
+
+type CompressStatement struct {
+	Compression string
+}
+
+func (s *CompressStatement) stmt() {}
+
+// ConnectStatement switch the server by the connection string
+type ConnectStatement struct {
+	DSN string
+}
+
+func (s *ConnectStatement) stmt() {}
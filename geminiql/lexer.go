@@ -72,7 +72,8 @@ func NewTokenizer(rd io.Reader) *Tokenizer {
 	}
 
 	for i, name := range QLToknames {
-		if i-3 < 0 {
+		// CONNECT is the keyword only after `\`, it is scanned by Scan
+		if i-3 < 0 || INSERT+i-3 == CONNECT {
 			continue
 		}
 		t.keywords[strings.ToUpper(name)] = INSERT + i - 3
@@ -105,13 +106,22 @@ func (t *Tokenizer) Scan() (tok int, val string) {
 	if t.firstToken() == INSERT && t.lastToken() == EQ {
 		return t.scanRaw()
 	}
+	// the connection string of \connect is scanned as it is
+	if t.firstToken() == CONNECT && t.lastToken() == WS_TOKEN && len(t.tokens) == 2 {
+		return t.scanUntilSpace()
+	}
 
 	ch := t.Lookahead()
 
 	if unicode.IsSpace(ch) {
 		return t.scanWhiteSpace()
 	} else if isLetter(ch) || isLegalSymbol(ch) {
-		return t.scanIdentifier()
+		tok, val = t.scanIdentifier()
+		// COMPRESS is the keyword only at the beginning, the database of `use compress` is an identifier
+		if tok == COMPRESS && len(t.tokens) != 0 {
+			tok = IDENT
+		}
+		return tok, val
 	} else if unicode.IsDigit(ch) {
 		return t.scanDigit()
 	}
@@ -130,6 +140,13 @@ func (t *Tokenizer) Scan() (tok int, val string) {
 	case ',':
 		ch = t.read()
 		return COMMA, string(ch)
+	case '\\':
+		// meta command such as \connect
+		_ = t.read()
+		if _, val := t.scanIdentifier(); strings.EqualFold(val, "connect") {
+			return CONNECT, val
+		}
+		return ILLEGAL_TOKEN, string(ch)
 	}
 
 	return ILLEGAL_TOKEN, ""
@@ -293,6 +310,22 @@ OutLoop:
 	return typ, buf.String()
 }
 
+func (t *Tokenizer) scanUntilSpace() (int, string) {
+	var buf bytes.Buffer
+
+	for {
+		ch := t.read()
+		if ch == EOF {
+			break
+		} else if unicode.IsSpace(ch) {
+			_ = t.unRead()
+			break
+		}
+		buf.WriteRune(ch)
+	}
+	return RAW, buf.String()
+}
+
 func (t *Tokenizer) scanWhiteSpace() (int, string) {
 	var buf bytes.Buffer
 
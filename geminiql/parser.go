@@ -57,14 +57,16 @@ const TIMER = 57355
 const DEBUG = 57356
 const PROMPT = 57357
 const VERTICAL = 57358
-const DOT = 57359
-const COMMA = 57360
-const EQ = 57361
-const IDENT = 57362
-const INTEGER = 57363
-const DECIMAL = 57364
-const STRING = 57365
-const RAW = 57366
+const COMPRESS = 57359
+const CONNECT = 57360
+const DOT = 57361
+const COMMA = 57362
+const EQ = 57363
+const IDENT = 57364
+const INTEGER = 57365
+const DECIMAL = 57366
+const STRING = 57367
+const RAW = 57368
 
 var QLToknames = [...]string{
 	"$end",
@@ -83,6 +85,8 @@ var QLToknames = [...]string{
 	"DEBUG",
 	"PROMPT",
 	"VERTICAL",
+	"COMPRESS",
+	"CONNECT",
 	"DOT",
 	"COMMA",
 	"EQ",
@@ -99,7 +103,7 @@ const QLEofCode = 1
 const QLErrCode = 2
 const QLInitialStackSize = 16
 
-//line parser.y:322
+//line parser.y:352
 
 //line yacctab:1
 var QLExca = [...]int8{
@@ -110,68 +114,68 @@ var QLExca = [...]int8{
 
 const QLPrivate = 57344
 
-const QLLast = 63
+const QLLast = 70
 
 var QLAct = [...]int8{
-	43, 33, 27, 31, 14, 61, 15, 16, 17, 18,
-	19, 20, 21, 22, 23, 24, 25, 55, 57, 58,
-	56, 42, 41, 45, 26, 37, 45, 35, 32, 30,
-	39, 38, 52, 48, 51, 47, 34, 46, 36, 30,
-	40, 44, 49, 50, 29, 28, 13, 12, 11, 54,
-	53, 59, 60, 10, 9, 8, 7, 6, 5, 4,
-	3, 2, 1,
+	50, 37, 31, 35, 16, 68, 17, 18, 19, 20,
+	21, 22, 23, 24, 25, 26, 27, 28, 29, 62,
+	64, 65, 63, 49, 45, 52, 30, 48, 41, 52,
+	39, 36, 34, 44, 46, 43, 42, 59, 55, 58,
+	54, 53, 38, 34, 40, 47, 51, 33, 32, 56,
+	57, 15, 14, 13, 12, 11, 61, 60, 66, 67,
+	10, 9, 8, 7, 6, 5, 4, 3, 2, 1,
 }
 
 var QLPact = [...]int16{
-	0, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, 19, 8, 7, -1000, 4, -1000,
-	-1000, 11, -1000, -1000, -1000, -1000, 8, -1000, 1, 3,
-	-1000, -1000, 20, -1000, 17, 14, -1000, -1000, -1000, 9,
-	-1000, -1000, 6, -1000, 16, 13, 8, 7, -3, -1000,
-	6, 6, -19, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000,
+	0, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, 21, 9, 8, -32768,
+	5, 14, -32768, 13, -32768, -32768, -32768, -32768, 11, -2,
+	9, -32768, 4, 3, -32768, -32768, 22, -32768, 20, 17,
+	-32768, -32768, -32768, -32768, -32768, -32768, 10, -32768, -32768, 7,
+	-32768, 19, 16, 9, 8, -3, -32768, 7, 7, -21,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
 }
 
 var QLPgo = [...]int8{
-	0, 62, 61, 60, 59, 58, 57, 56, 55, 54,
-	53, 48, 47, 46, 2, 45, 44, 41, 0, 40,
-	38, 3, 36, 1,
+	0, 69, 68, 67, 66, 65, 64, 63, 62, 61,
+	60, 55, 54, 53, 52, 51, 2, 48, 47, 46,
+	0, 45, 44, 3, 42, 1,
 }
 
 var QLR1 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 4, 3, 2, 2, 5, 6, 20,
-	7, 8, 9, 10, 11, 12, 13, 21, 21, 14,
-	14, 15, 15, 22, 22, 22, 22, 23, 23, 18,
-	18, 17, 16, 19,
+	1, 1, 1, 1, 1, 4, 3, 2, 2, 5,
+	6, 22, 7, 7, 8, 9, 10, 11, 12, 13,
+	14, 15, 23, 23, 16, 16, 17, 17, 24, 24,
+	24, 24, 25, 25, 20, 20, 19, 18, 21,
 }
 
 var QLR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 2, 2, 4, 2, 1, 2, 1,
-	1, 1, 2, 1, 1, 1, 1, 1, 3, 1,
-	2, 4, 2, 3, 3, 3, 3, 1, 3, 1,
-	3, 3, 1, 1,
+	1, 1, 1, 1, 1, 2, 2, 4, 2, 1,
+	2, 1, 1, 2, 1, 2, 1, 1, 1, 1,
+	2, 2, 1, 3, 1, 2, 4, 2, 3, 3,
+	3, 3, 1, 3, 1, 3, 3, 1, 1,
 }
 
 var QLChk = [...]int16{
-	-1000, -1, -2, -3, -4, -5, -6, -7, -8, -9,
-	-10, -11, -12, -13, 4, 6, 7, 8, 9, 10,
-	11, 12, 13, 14, 15, 16, 5, -14, -15, -16,
-	20, -21, 20, -23, -22, 20, -20, 21, 20, -21,
-	-19, 21, 18, -18, -17, 20, 17, 18, 19, -14,
-	-18, 18, 19, -21, -23, 20, 23, 21, 22, -18,
-	-18, 24,
+	-32768, -1, -2, -3, -4, -5, -6, -7, -8, -9,
+	-10, -11, -12, -13, -14, -15, 4, 6, 7, 8,
+	9, 10, 11, 12, 13, 14, 15, 16, 17, 18,
+	5, -16, -17, -18, 22, -23, 22, -25, -24, 22,
+	-22, 23, 22, 22, 22, 26, -23, -21, 23, 20,
+	-20, -19, 22, 19, 20, 21, -16, -20, 20, 21,
+	-23, -25, 22, 25, 23, 24, -20, -20, 26,
 }
 
 var QLDef = [...]int8{
 	0, -2, 1, 2, 3, 4, 5, 6, 7, 8,
-	9, 10, 11, 12, 0, 0, 0, 17, 0, 20,
-	21, 0, 23, 24, 25, 26, 0, 16, 29, 0,
-	42, 14, 27, 13, 37, 0, 18, 19, 22, 0,
-	30, 43, 0, 32, 39, 0, 0, 0, 0, 15,
-	0, 0, 0, 28, 38, 33, 34, 35, 36, 31,
-	40, 41,
+	9, 10, 11, 12, 13, 14, 0, 0, 0, 19,
+	0, 22, 24, 0, 26, 27, 28, 29, 0, 0,
+	0, 18, 34, 0, 47, 16, 32, 15, 42, 0,
+	20, 21, 23, 25, 30, 31, 0, 35, 48, 0,
+	37, 44, 0, 0, 0, 0, 17, 0, 0, 0,
+	33, 43, 38, 39, 40, 41, 36, 45, 46,
 }
 
 var QLTok1 = [...]int8{
@@ -181,7 +185,7 @@ var QLTok1 = [...]int8{
 var QLTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
-	22, 23, 24,
+	22, 23, 24, 25, 26,
 }
 
 var QLTok3 = [...]int8{
@@ -200,7 +204,7 @@ var QLErrorMessages = [...]struct {
 
 var (
 	QLDebug        = 0
-	QLErrorVerbose = true
+	QLErrorVerbose = false
 )
 
 type QLLexer interface {
@@ -227,7 +231,7 @@ func QLNewParser() QLParser {
 	return &QLParserImpl{}
 }
 
-const QLFlag = -1000
+const QLFlag = -32768
 
 func QLTokname(c int) string {
 	if c >= 1 && c-1 < len(QLToknames) {
@@ -598,16 +602,28 @@ QLdefault:
 			updateStmt(QLlex, QLDollar[1].stmt)
 		}
 	case 13:
+		QLDollar = QLS[QLpt-1 : QLpt+1]
+//line parser.y:115
+		{
+			updateStmt(QLlex, QLDollar[1].stmt)
+		}
+	case 14:
+		QLDollar = QLS[QLpt-1 : QLpt+1]
+//line parser.y:119
+		{
+			updateStmt(QLlex, QLDollar[1].stmt)
+		}
+	case 15:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:117
+//line parser.y:125
 		{
 			stmt := &SetStatement{}
 			stmt.KVS = QLDollar[2].pairs
 			QLVAL.stmt = stmt
 		}
-	case 14:
+	case 16:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:125
+//line parser.y:133
 		{
 			stmt := &UseStatement{}
 			if len(QLDollar[2].strslice) == 1 {
@@ -621,9 +637,9 @@ QLdefault:
 				QLlex.Error("namespace must be <db>.<rp>")
 			}
 		}
-	case 15:
+	case 17:
 		QLDollar = QLS[QLpt-4 : QLpt+1]
-//line parser.y:141
+//line parser.y:149
 		{
 			stmt := &InsertStatement{}
 			stmt.LineProtocol = QLDollar[4].str
@@ -636,189 +652,213 @@ QLdefault:
 				QLVAL.stmt = stmt
 			}
 		}
-	case 16:
+	case 18:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:154
+//line parser.y:162
 		{
 			stmt := &InsertStatement{}
 			stmt.LineProtocol = QLDollar[2].str
 			QLVAL.stmt = stmt
 		}
-	case 17:
+	case 19:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:162
+//line parser.y:170
 		{
 			stmt := &ChunkedStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 18:
+	case 20:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:169
+//line parser.y:177
 		{
 			stmt := &ChunkSizeStatement{}
 			stmt.Size = QLDollar[2].integer
 			QLVAL.stmt = stmt
 		}
-	case 19:
+	case 21:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:177
+//line parser.y:185
 		{
 			QLVAL.integer = QLDollar[1].integer
 		}
-	case 20:
+	case 22:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:183
+//line parser.y:191
 		{
 			stmt := &AuthStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 21:
+	case 23:
+		QLDollar = QLS[QLpt-2 : QLpt+1]
+//line parser.y:196
+		{
+			stmt := &AuthStatement{}
+			stmt.Method = QLDollar[2].str
+			QLVAL.stmt = stmt
+		}
+	case 24:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:190
+//line parser.y:204
 		{
 			stmt := &HelpStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 22:
+	case 25:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:197
+//line parser.y:211
 		{
 			stmt := &PrecisionStatement{}
 			stmt.Precision = QLDollar[2].str
 			QLVAL.stmt = stmt
 		}
-	case 23:
+	case 26:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:205
+//line parser.y:219
 		{
 			stmt := &TimerStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 24:
+	case 27:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:212
+//line parser.y:226
 		{
 			stmt := &DebugStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 25:
+	case 28:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:219
+//line parser.y:233
 		{
 			stmt := &PromptStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 26:
+	case 29:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:226
+//line parser.y:240
 		{
 			stmt := &VerticalStatement{}
 			QLVAL.stmt = stmt
 		}
-	case 27:
+	case 30:
+		QLDollar = QLS[QLpt-2 : QLpt+1]
+//line parser.y:247
+		{
+			stmt := &CompressStatement{}
+			stmt.Compression = QLDollar[2].str
+			QLVAL.stmt = stmt
+		}
+	case 31:
+		QLDollar = QLS[QLpt-2 : QLpt+1]
+//line parser.y:255
+		{
+			stmt := &ConnectStatement{}
+			stmt.DSN = QLDollar[2].str
+			QLVAL.stmt = stmt
+		}
+	case 32:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:233
+//line parser.y:263
 		{
 			QLVAL.strslice = []string{QLDollar[1].str}
 		}
-	case 28:
+	case 33:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:237
+//line parser.y:267
 		{
 			ns := []string{QLDollar[1].str}
 			QLVAL.strslice = append(ns, QLDollar[3].strslice...)
 		}
-	case 29:
+	case 34:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:244
+//line parser.y:274
 		{
 			QLVAL.str = QLDollar[1].str
 		}
-	case 30:
+	case 35:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:248
+//line parser.y:278
 		{
 			QLVAL.str = QLDollar[1].str + " " + QLDollar[2].str
 		}
-	case 31:
+	case 36:
 		QLDollar = QLS[QLpt-4 : QLpt+1]
-//line parser.y:254
+//line parser.y:284
 		{
 			QLVAL.str = QLDollar[1].str + QLDollar[2].str + QLDollar[3].str + " " + QLDollar[4].str
 		}
-	case 32:
+	case 37:
 		QLDollar = QLS[QLpt-2 : QLpt+1]
-//line parser.y:258
+//line parser.y:288
 		{
 			QLVAL.str = QLDollar[1].str + " " + QLDollar[2].str
 		}
-	case 33:
+	case 38:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:264
+//line parser.y:294
 		{
 			p := NewPair(QLDollar[1].str, QLDollar[3].str)
 			QLVAL.pair = *p
 		}
-	case 34:
+	case 39:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:269
+//line parser.y:299
 		{
 			p := NewPair(QLDollar[1].str, QLDollar[3].str)
 			QLVAL.pair = *p
 		}
-	case 35:
+	case 40:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:274
+//line parser.y:304
 		{
 			p := NewPair(QLDollar[1].str, QLDollar[3].integer)
 			QLVAL.pair = *p
 		}
-	case 36:
+	case 41:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:279
+//line parser.y:309
 		{
 			p := NewPair(QLDollar[1].str, QLDollar[3].decimal)
 			QLVAL.pair = *p
 		}
-	case 37:
+	case 42:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:286
+//line parser.y:316
 		{
 			QLVAL.pairs = Pairs{QLDollar[1].pair}
 		}
-	case 38:
+	case 43:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:290
+//line parser.y:320
 		{
 			QLVAL.pairs = append(QLDollar[3].pairs, QLDollar[1].pair)
 		}
-	case 39:
+	case 44:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:296
+//line parser.y:326
 		{
 			QLVAL.str = QLDollar[1].str
 		}
-	case 40:
+	case 45:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:300
+//line parser.y:330
 		{
 			QLVAL.str = QLDollar[1].str + QLDollar[2].str + QLDollar[3].str
 		}
-	case 41:
+	case 46:
 		QLDollar = QLS[QLpt-3 : QLpt+1]
-//line parser.y:306
+//line parser.y:336
 		{
 			QLVAL.str = QLDollar[1].str + QLDollar[2].str + QLDollar[3].str
 		}
-	case 42:
+	case 47:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:312
+//line parser.y:342
 		{
 			QLVAL.str = QLDollar[1].str
 		}
-	case 43:
+	case 48:
 		QLDollar = QLS[QLpt-1 : QLpt+1]
-//line parser.y:318
+//line parser.y:348
 		{
 			QLVAL.str = strconv.FormatInt(QLDollar[1].integer, 10)
 		}
@@ -43,7 +43,7 @@ func updateStmt(QLlex interface{}, stmt Statement) {
 // any non-terminal which returns a value needs a type, which is
 // really a field name in the above union struct
 %type <stmts> STATEMENTS
-%type <stmt> INSERT_STATEMENT USE_STATEMENT SET_STATEMENT CHUNKED_STATEMENT CHUNK_SIZE_STATEMENT AUTH_STATEMENT HELP_STATEMENT PRECISION_STATEMENT TIMER_STATEMENT DEBUG_STATEMENT PROMPT_STATEMENT VERTICAL_STATEMENT
+%type <stmt> INSERT_STATEMENT USE_STATEMENT SET_STATEMENT CHUNKED_STATEMENT CHUNK_SIZE_STATEMENT AUTH_STATEMENT HELP_STATEMENT PRECISION_STATEMENT TIMER_STATEMENT DEBUG_STATEMENT PROMPT_STATEMENT VERTICAL_STATEMENT COMPRESS_STATEMENT CONNECT_STATEMENT
 %type <str> LINE_PROTOCOL TIME_SERIE MEASUREMENT KV_RAW KV_RAWS TIME
 %type <integer> NUM_CHUNK_SIZE
 %type <strslice> NAMESPACE
@@ -51,7 +51,7 @@ func updateStmt(QLlex interface{}, stmt Statement) {
 %type <pairs> KEY_VALUES
 
 // same for terminals
-%token <str> INSERT INTO USE SET CHUNKED CHUNK_SIZE AUTH HELP PRECISION TIMER DEBUG PROMPT VERTICAL
+%token <str> INSERT INTO USE SET CHUNKED CHUNK_SIZE AUTH HELP PRECISION TIMER DEBUG PROMPT VERTICAL COMPRESS CONNECT
 %token <str> DOT COMMA
 %token <str> EQ
 %token <str> IDENT
@@ -111,6 +111,14 @@ STATEMENTS:
     {
         updateStmt(QLlex, $1)
     }
+    |COMPRESS_STATEMENT
+    {
+        updateStmt(QLlex, $1)
+    }
+    |CONNECT_STATEMENT
+    {
+        updateStmt(QLlex, $1)
+    }
 
 SET_STATEMENT:
     SET KEY_VALUES
@@ -184,6 +192,12 @@ AUTH_STATEMENT:
         stmt := &AuthStatement{}
         $$ = stmt
     }
+    |AUTH IDENT
+    {
+        stmt := &AuthStatement{}
+        stmt.Method = $2
+        $$ = stmt
+    }
 
 HELP_STATEMENT:
     HELP
@@ -228,6 +242,22 @@ VERTICAL_STATEMENT:
         $$ = stmt
     }
 
+COMPRESS_STATEMENT:
+    COMPRESS IDENT
+    {
+        stmt := &CompressStatement{}
+        stmt.Compression = $2
+        $$ = stmt
+    }
+
+CONNECT_STATEMENT:
+    CONNECT RAW
+    {
+        stmt := &ConnectStatement{}
+        stmt.DSN = $2
+        $$ = stmt
+    }
+
 NAMESPACE:
     IDENT
     {
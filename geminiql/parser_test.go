@@ -269,6 +269,11 @@ func TestParser(t *testing.T) {
 			cmd:    "auth",
 			expect: &AuthStatement{},
 		},
+		{
+			name:   "auth with token",
+			cmd:    "auth token",
+			expect: &AuthStatement{Method: "token"},
+		},
 		{
 			name: "set precision",
 			cmd:  "precision ns",
@@ -276,6 +281,44 @@ func TestParser(t *testing.T) {
 				Precision: "ns",
 			},
 		},
+		{
+			name: "connect to server",
+			cmd:  `\connect opengemini+https://user@[::1]:8086/db0?rp=autogen&precision=ms`,
+			expect: &ConnectStatement{
+				DSN: "opengemini+https://user@[::1]:8086/db0?rp=autogen&precision=ms",
+			},
+		},
+		{
+			name: "set compression",
+			cmd:  "compress gzip",
+			expect: &CompressStatement{
+				Compression: "gzip",
+			},
+		},
+		{
+			name:   "use database named compress",
+			cmd:    "use compress",
+			expect: &UseStatement{DB: "compress"},
+		},
+		{
+			name:   "use database named connect",
+			cmd:    "use connect",
+			expect: &UseStatement{DB: "connect"},
+		},
+		{
+			name: "insert measurement named compress",
+			cmd:  "insert compress v=1",
+			expect: &InsertStatement{
+				LineProtocol: "compress v=1",
+			},
+		},
+		{
+			name: "insert measurement named connect",
+			cmd:  "insert connect v=1",
+			expect: &InsertStatement{
+				LineProtocol: "connect v=1",
+			},
+		},
 		{
 			name: "tag array write with multi values",
 			cmd:  "insert cpu,t1=1,t2=[a,b] value=3",